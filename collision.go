@@ -0,0 +1,115 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// collisions walks the fields like parse does, without reading any env, and
+// records the Go field path of every resolved env name.
+type collisions struct {
+	fields map[string]collisionField
+	errs   *Errors
+}
+
+type collisionField struct {
+	path   string
+	shared bool
+}
+
+/*
+`env:"TZ,shared"` lets fields read the same env on purpose, every field of the name must be shared.
+*/
+func checkCollisions(p Payload) error {
+	c := &collisions{fields: map[string]collisionField{}, errs: &Errors{}}
+	c.walk(p, map[reflect.Type]bool{})
+	if len(c.errs.Errors) == 0 || p.Opt.Enable(OptSilent) && !p.Opt.Enable(OptCollect) {
+		return nil
+	}
+	if !p.Opt.Enable(OptCollect) {
+		return c.errs.Errors[0]
+	}
+	return c.errs
+}
+
+func (c *collisions) walk(p Payload, visiting map[reflect.Type]bool) {
+	typ := p.Value.Type()
+	if visiting[typ] {
+		return
+	}
+	visiting[typ] = true
+	defer delete(visiting, typ)
+	path := p.path
+	outer, shadowed := p.shadowed, p.shadow()
+	for i := 0; i < p.Value.NumField(); i++ {
+		p.Field = p.Value.Field(i)
+		p.StructField = typ.Field(i)
+		p.path = joinPath(path, p.StructField.Name)
+		if p.unexported() || p.isShadowed(outer, shadowed) {
+			continue
+		}
+		c.walkField(p, visiting)
+	}
+}
+
+func (c *collisions) walkField(p Payload, visiting map[reflect.Type]bool) {
+	if decoderOf(p) != nil {
+		c.add(p)
+		return
+	}
+	t := p.tag()
+	switch p.Field.Kind() {
+	case reflect.Ptr:
+		if p.Field.IsNil() {
+			p.Field = reflect.New(p.Field.Type().Elem()).Elem()
+		} else {
+			p.Field = p.Field.Elem()
+		}
+		c.walkField(p, visiting)
+	case reflect.Struct:
+		p.Value = p.Field
+		p.Prefix = p.structPrefix(t)
+		c.walk(p, visiting)
+	case reflect.Map:
+		// names of prefixmap are only known from the env.
+		if !t.PrefixMap {
+			c.add(p)
+		}
+	case reflect.Slice:
+		// struct slices read indexed names like NAME_0_FIELD.
+		if !isStruct(p.Field.Type().Elem()) {
+			c.add(p)
+		}
+	default:
+		c.add(p)
+	}
+}
+
+// add registers the name and every alias of the field.
+func (c *collisions) add(p Payload) {
+	t := p.tag()
+	for _, name := range append([]string{t.Name}, t.Aliases...) {
+		c.addName(p, t, p.envName(t, name))
+	}
+}
+
+func (c *collisions) addName(p Payload, t tag, name string) {
+	if name == "" {
+		return
+	}
+	first, exist := c.fields[name]
+	if !exist {
+		c.fields[name] = collisionField{path: p.path, shared: t.Shared}
+		return
+	}
+	if first.path == p.path || first.shared && t.Shared {
+		return
+	}
+	c.errs.Errors = append(c.errs.Errors, &FieldError{
+		Path:    p.path,
+		EnvName: name,
+		Kind:    p.Field.Kind(),
+		Err:     ErrCollision,
+		Cause:   fmt.Errorf("also read by %s", first.path),
+	})
+}
@@ -0,0 +1,293 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// SyntaxError reports the line of a malformed dotenv file.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("dotenv: line %d: %s", e.Line, e.Msg)
+}
+
+// ReadDotenvFile reads a dotenv file as a MapEnv source.
+func ReadDotenvFile(filename string) (MapEnv, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadDotenv(f)
+}
+
+// LoadDotenv populates the process environment from dotenv files, variables
+// which already exist are not overridden.
+func LoadDotenv(filenames ...string) error {
+	if len(filenames) == 0 {
+		filenames = []string{".env"}
+	}
+	for _, filename := range filenames {
+		m, err := ReadDotenvFile(filename)
+		if err != nil {
+			return err
+		}
+		for key, value := range m {
+			if _, exist := os.LookupEnv(key); exist {
+				continue
+			}
+			if err := os.Setenv(key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+/*
+ReadDotenv reads dotenv content like:
+
+	# comment
+	export ADDR=localhost:5432 # inline comment
+	USER='postgres'
+	PASSWORD="line1\nline2"
+	CERT="-----BEGIN CERTIFICATE-----
+	...
+	-----END CERTIFICATE-----"
+	URL=postgres://${USER}@$ADDR
+
+single quoted values are literal, double quoted values support escape
+sequences, both can span lines. `${VAR}` and `$VAR` are interpolated from
+the previous lines and then the process environment, except in single quotes.
+*/
+func ReadDotenv(r io.Reader) (MapEnv, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	d := &dotenv{src: strings.ReplaceAll(string(b), "\r\n", "\n"), line: 1, vars: MapEnv{}}
+	return d.vars, d.parse()
+}
+
+type dotenv struct {
+	src  string
+	pos  int
+	line int
+	vars MapEnv
+}
+
+func (d *dotenv) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Line: d.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (d *dotenv) eof() bool {
+	return d.pos >= len(d.src)
+}
+
+func (d *dotenv) peek() byte {
+	return d.src[d.pos]
+}
+
+func (d *dotenv) next() byte {
+	c := d.src[d.pos]
+	d.pos++
+	if c == '\n' {
+		d.line++
+	}
+	return c
+}
+
+func (d *dotenv) skipBlank() {
+	for !d.eof() && (d.peek() == ' ' || d.peek() == '\t') {
+		d.pos++
+	}
+}
+
+func (d *dotenv) skipLine() {
+	for !d.eof() && d.next() != '\n' {
+	}
+}
+
+func (d *dotenv) parse() error {
+	for {
+		for !d.eof() && strings.IndexByte(" \t\n", d.peek()) >= 0 {
+			d.next()
+		}
+		if d.eof() {
+			return nil
+		}
+		if d.peek() == '#' {
+			d.skipLine()
+			continue
+		}
+		key := d.key()
+		if key == "export" && !d.eof() && (d.peek() == ' ' || d.peek() == '\t') {
+			d.skipBlank()
+			key = d.key()
+		}
+		if key == "" {
+			return d.errorf("invalid key")
+		}
+		d.skipBlank()
+		if d.eof() || d.peek() != '=' {
+			return d.errorf("missing = after %s", key)
+		}
+		d.pos++
+		d.skipBlank()
+		value, err := d.value()
+		if err != nil {
+			return err
+		}
+		d.vars[key] = value
+	}
+}
+
+func (d *dotenv) key() string {
+	start := d.pos
+	for !d.eof() && isKeyChar(d.peek()) {
+		d.pos++
+	}
+	return d.src[start:d.pos]
+}
+
+func isKeyChar(c byte) bool {
+	return c == '.' || c == '-' || isNameChar(c)
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (d *dotenv) value() (string, error) {
+	if d.eof() {
+		return "", nil
+	}
+	var value string
+	var err error
+	switch d.peek() {
+	case '\'':
+		value, err = d.singleQuoted()
+	case '"':
+		value, err = d.doubleQuoted()
+	default:
+		return d.unquoted()
+	}
+	if err != nil {
+		return "", err
+	}
+	d.skipBlank()
+	if !d.eof() && d.peek() != '\n' && d.peek() != '#' {
+		return "", d.errorf("unexpected character %q after quoted value", d.peek())
+	}
+	d.skipLine()
+	return value, nil
+}
+
+func (d *dotenv) singleQuoted() (string, error) {
+	line := d.line
+	d.next()
+	start := d.pos
+	for !d.eof() {
+		if d.next() == '\'' {
+			return d.src[start : d.pos-1], nil
+		}
+	}
+	return "", &SyntaxError{Line: line, Msg: "unterminated single quote"}
+}
+
+func (d *dotenv) doubleQuoted() (string, error) {
+	line := d.line
+	d.next()
+	var sb strings.Builder
+	for !d.eof() {
+		c := d.next()
+		switch c {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			if d.eof() {
+				break
+			}
+			switch e := d.next(); e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '"', '\\', '$':
+				sb.WriteByte(e)
+			default:
+				sb.WriteByte('\\')
+				sb.WriteByte(e)
+			}
+		case '$':
+			value, err := d.interpolate()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", &SyntaxError{Line: line, Msg: "unterminated double quote"}
+}
+
+func (d *dotenv) unquoted() (string, error) {
+	var sb strings.Builder
+	for !d.eof() && d.peek() != '\n' {
+		c := d.next()
+		if c == '#' && (sb.Len() == 0 || strings.HasSuffix(sb.String(), " ") || strings.HasSuffix(sb.String(), "\t")) {
+			d.skipLine()
+			break
+		}
+		if c == '$' {
+			value, err := d.interpolate()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// interpolate reads `${VAR}` or `$VAR` after `$`.
+func (d *dotenv) interpolate() (string, error) {
+	if d.eof() {
+		return "$", nil
+	}
+	if d.peek() == '{' {
+		end := strings.IndexByte(d.src[d.pos:], '}')
+		if end < 0 || strings.ContainsRune(d.src[d.pos:d.pos+end], '\n') {
+			return "", d.errorf("unterminated ${")
+		}
+		name := d.src[d.pos+1 : d.pos+end]
+		d.pos += end + 1
+		return d.lookup(name), nil
+	}
+	start := d.pos
+	for !d.eof() && isNameChar(d.peek()) {
+		d.pos++
+	}
+	if start == d.pos {
+		return "$", nil
+	}
+	return d.lookup(d.src[start:d.pos]), nil
+}
+
+func (d *dotenv) lookup(name string) string {
+	if value, exist := d.vars[name]; exist {
+		return value
+	}
+	return os.Getenv(name)
+}
@@ -1,12 +1,18 @@
 package env
 
 import (
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
+	"math"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Opt int
@@ -18,25 +24,107 @@ func (o Opt) Enable(opt Opt) bool {
 const (
 	OptEnv Opt = 1 << iota
 	OptDefault
-	OptSilent // ignore err and iterate over all fields.
+	OptSilent   // ignore err and iterate over all fields.
+	OptCollect  // iterate over all fields and return every err as *Errors.
+	OptExpand   // expand $VAR, ${VAR} and ${VAR:-fallback} in values and defaults.
+	OptFile     // read the value from the file named by NAME_FILE when NAME is absent.
+	OptOverride // env value overrides the pre-populated field, env set to empty string clears it.
+	OptInline   // promote fields of anonymous embedded structs into the parent's namespace.
 )
 
+// ParserFunc converts an env value into a value of the registered type.
+type ParserFunc func(value string) (interface{}, error)
+
 type Entity struct {
-	Value  interface{}
-	Prefix string
-	Opt    Opt
+	Value   interface{}
+	Prefix  string
+	Opt     Opt
+	Parsers map[reflect.Type]ParserFunc
+	Source  Lookuper // default is OSEnv.
+	// Origins records the Layer name, "env" or OriginDefault of each field
+	// value by Go field path when it is not nil.
+	Origins map[string]string
+	// NameMapper derives env names from field names, default is NameUpper.
+	NameMapper NameMapper
+	// OnDeprecated is called when a deprecated alias supplies the value,
+	// default is log.Printf.
+	OnDeprecated func(name, replacement string)
 }
 
 type Payload struct {
-	Value       reflect.Value
-	Prefix      string
-	Opt         Opt
-	Field       reflect.Value
-	StructField reflect.StructField
+	Value        reflect.Value
+	Prefix       string
+	Opt          Opt
+	Parsers      map[reflect.Type]ParserFunc
+	Source       Lookuper
+	Origins      map[string]string
+	NameMapper   NameMapper
+	OnDeprecated func(name, replacement string)
+	Field        reflect.Value
+	StructField  reflect.StructField
+	found        *int
+	path         string
+	// shadowed holds names of the outer fields when parsing an inline struct.
+	shadowed map[string]bool
+	// visiting holds struct types being parsed, nil pointers back to them are left nil.
+	visiting map[reflect.Type]bool
+	// addrs holds addresses of structs being parsed, pointers back to them are not followed.
+	addrs map[addr]bool
+}
+
+type addr struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func (p Payload) withField(field reflect.Value) Payload {
+	p.Field = field
+	return p
+}
+
+func (p Payload) withPrefix(value reflect.Value, prefix, path string) Payload {
+	p.Value = value
+	p.Prefix = prefix
+	p.path = path
+	return p
+}
+
+func markFound(found *int) {
+	if found != nil {
+		*found++
+	}
+}
+
+// Decoder is implemented by types that parse their own env value.
+type Decoder interface {
+	DecodeEnv(value string) error
 }
 
 var NotPointerStructErr = errors.New("only supported pointer to `struct`")
 
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	locationType = reflect.TypeOf(&time.Location{})
+)
+
+var layouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    "2006-01-02 15:04:05",
+	"DateOnly":    "2006-01-02",
+	"TimeOnly":    "15:04:05",
+}
+
 func Parse(v interface{}) error {
 	return ParseEntity(Entity{
 		Value: v,
@@ -49,22 +137,131 @@ func ParseEntity(e Entity) error {
 	if reflect.ValueOf(e.Value).Kind() != reflect.Ptr || ind.Kind() != reflect.Struct {
 		return NotPointerStructErr
 	}
-	return parse(Payload{Value: ind, Prefix: e.Prefix, Opt: e.Opt})
+	if e.Source == nil {
+		e.Source = OSEnv{}
+	}
+	p := Payload{
+		Value:        ind,
+		Prefix:       e.Prefix,
+		Opt:          e.Opt,
+		Parsers:      e.Parsers,
+		Source:       e.Source,
+		Origins:      e.Origins,
+		NameMapper:   e.NameMapper,
+		OnDeprecated: e.OnDeprecated,
+	}
+	if p.Opt.Enable(OptEnv) {
+		if err := checkCollisions(p); err != nil {
+			return err
+		}
+	}
+	return parse(p)
 }
 
 func parse(p Payload) error {
+	errs := &Errors{}
+	path := p.path
+	if p.visiting == nil {
+		p.visiting = map[reflect.Type]bool{}
+	}
+	if typ := p.Value.Type(); !p.visiting[typ] {
+		p.visiting[typ] = true
+		defer delete(p.visiting, typ)
+	}
+	if p.addrs == nil {
+		p.addrs = map[addr]bool{}
+	}
+	if p.Value.CanAddr() {
+		a := addr{p.Value.Addr().Pointer(), p.Value.Type()}
+		p.addrs[a] = true
+		defer delete(p.addrs, a)
+	}
+	outer, shadowed := p.shadowed, p.shadow()
 	for i := 0; i < p.Value.NumField(); i++ {
 		p.Field = p.Value.Field(i)
 		p.StructField = p.Value.Type().Field(i)
+		p.path = joinPath(path, p.StructField.Name)
+		if p.unexported() || p.isShadowed(outer, shadowed) {
+			continue
+		}
 		err := parseField(p)
-		if err != nil && !p.Opt.Enable(OptSilent) {
+		if err == nil {
+			continue
+		}
+		if p.Opt.Enable(OptCollect) {
+			errs.add(p, err)
+			continue
+		}
+		if !p.Opt.Enable(OptSilent) {
 			return err
 		}
 	}
-	return nil
+	if len(errs.Errors) != 0 {
+		return errs
+	}
+	return validateStruct(p.Value, path, p.Prefix)
+}
+
+// unexported reports whether p.StructField can not be set, fields of embedded
+// structs are still promoted.
+func (p Payload) unexported() bool {
+	return p.StructField.PkgPath != "" && !(p.StructField.Anonymous && isStruct(p.StructField.Type))
+}
+
+// shadow returns names of the outer fields and the direct fields of p.Value
+// that are not inline, fields of inline structs under these names are skipped.
+func (p Payload) shadow() map[string]bool {
+	shadowed := map[string]bool{}
+	for name := range p.shadowed {
+		shadowed[name] = true
+	}
+	for i := 0; i < p.Value.NumField(); i++ {
+		p.StructField = p.Value.Type().Field(i)
+		t := p.tag()
+		if !p.inline(t) {
+			shadowed[p.envName(t, t.Name)] = true
+		}
+	}
+	return shadowed
+}
+
+// isShadowed reports whether p.StructField is hidden by an outer field with
+// the same name, and passes the names down when it is an inline struct.
+func (p *Payload) isShadowed(outer, shadowed map[string]bool) bool {
+	t := p.tag()
+	if p.inline(t) {
+		p.shadowed = shadowed
+		return false
+	}
+	p.shadowed = nil
+	return outer[p.envName(t, t.Name)]
+}
+
+/*
+`env:",inline"` or `env:",squash"` promotes fields of the anonymous embedded struct into the parent's namespace,
+OptInline does it for every anonymous struct without a name in the tag.
+*/
+func (p Payload) inline(t tag) bool {
+	if !p.StructField.Anonymous || !isStruct(p.StructField.Type) {
+		return false
+	}
+	return t.Inline || p.Opt.Enable(OptInline) && !t.Named
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
 }
 
 func parseField(p Payload) error {
+	if t := p.tag(); len(t.Unknown) != 0 {
+		return fieldError(p, ErrInvalidTag, t.Unknown[0], nil)
+	}
+	if decode := decoderOf(p); decode != nil {
+		return parseDecoder(p, decode)
+	}
 	switch p.Field.Kind() {
 	case reflect.Ptr:
 		return parsePtr(p)
@@ -89,127 +286,562 @@ func parseField(p Payload) error {
 	case reflect.Bool:
 		return parseBool(p)
 	default:
-		return fmt.Errorf("unsupport field [%s] kind [%v]", p.StructField.Name, p.Field.Kind())
+		return fieldError(p, ErrUnsupportedType, "", nil)
+	}
+}
+
+/*
+`env:"field,sep=_,default=df,require,empty,delim=;,kvsep=:,prefixmap,exact,encoding=hex,sparse,optional,unit=s,layout=RFC3339,expand,file,secret,notempty,min=1,max=9,oneof=a|b,match=^[a-z]+$,alias=A|B,deprecated=A,noprefix,shared,inline"`
+*/
+type tag struct {
+	Name    string
+	Default string
+	Require bool
+	Sep     string
+	Delim   string
+	KVSep   string
+	// PrefixMap collects every `NAME<sep><KEY>` variable into a map.
+	PrefixMap bool
+	// Exact requires an array to be filled completely.
+	Exact    bool
+	Encoding string
+	// Sparse allows gaps between indexes of a struct slice.
+	Sparse bool
+	// Optional leaves a pointer nil when none of its env is set.
+	Optional bool
+	Unit     string
+	Layout   string
+	// Expand expands references like ${VAR} in the value.
+	Expand bool
+	// File reads the value from the file named by NAME_FILE when NAME is absent.
+	File bool
+	// Secret redacts the value in errors.
+	Secret bool
+	// rules of validate.
+	NotEmpty bool
+	Min      string
+	Max      string
+	OneOf    []string
+	Match    string
+	// Aliases are looked up in order after Name.
+	Aliases    []string
+	Deprecated []string
+	// NoPrefix ignores the inherited prefix, `env:"/NAME"` is the same as `env:"NAME,noprefix"`.
+	NoPrefix bool
+	// Shared allows other shared fields to read the same env.
+	Shared bool
+	// Named is set when the tag gives the name.
+	Named  bool
+	Inline bool
+	// Unknown holds options parseTag does not know, they fail the field.
+	Unknown []string
+}
+
+func (p Payload) tag() tag {
+	return parseTag(p.StructField, p.NameMapper)
+}
+
+func parseTag(sf reflect.StructField, mapper NameMapper) tag {
+	if mapper == nil {
+		mapper = NameUpper
+	}
+	t := tag{
+		Name:  mapper(sf.Name),
+		Sep:   nameSep(mapper),
+		Delim: ",",
+		KVSep: "=",
 	}
+	envStr, exist := sf.Tag.Lookup("env")
+	if !exist {
+		return t
+	}
+	matching := false
+	for index, str := range strings.Split(envStr, ",") {
+		if index == 0 {
+			if strings.HasPrefix(str, "/") {
+				str, t.NoPrefix = str[1:], true
+			}
+			if str != "" {
+				t.Name, t.Named = str, true
+			}
+			continue
+		}
+		key, value := str, ""
+		if i := strings.Index(str, "="); i >= 0 {
+			key, value = str[:i], str[i+1:]
+		}
+		switch key {
+		case "require":
+			t.Require = true
+		case "default":
+			t.Default = value
+		case "sep":
+			t.Sep = value
+		case "empty":
+			t.Name = ""
+		case "delim":
+			t.Delim = value
+		case "kvsep":
+			t.KVSep = value
+		case "prefixmap":
+			t.PrefixMap = true
+		case "exact":
+			t.Exact = true
+		case "encoding":
+			t.Encoding = value
+		case "sparse":
+			t.Sparse = true
+		case "optional":
+			t.Optional = true
+		case "unit":
+			t.Unit = value
+		case "layout":
+			t.Layout = value
+		case "expand":
+			t.Expand = true
+		case "file":
+			t.File = true
+		case "secret":
+			t.Secret = true
+		case "notempty":
+			t.NotEmpty = true
+		case "min":
+			t.Min = value
+		case "max":
+			t.Max = value
+		case "oneof":
+			t.OneOf = strings.Split(value, "|")
+		case "match":
+			t.Match = value
+		case "alias":
+			t.Aliases = strings.Split(value, "|")
+		case "deprecated":
+			t.Deprecated = strings.Split(value, "|")
+		case "noprefix":
+			t.NoPrefix = true
+		case "shared":
+			t.Shared = true
+		case "inline", "squash":
+			t.Inline = true
+		default:
+			// "," in the expression of match is kept, like "match=^[a-z]{1,3}$".
+			if matching {
+				t.Match += "," + str
+				continue
+			}
+			if str != "" {
+				t.Unknown = append(t.Unknown, str)
+			}
+		}
+		matching = key == "match"
+	}
+	return t
+}
+
+// envName joins name to the prefix unless the tag is noprefix.
+func (p Payload) envName(t tag, name string) string {
+	if t.NoPrefix {
+		return name
+	}
+	return joinName(p.Prefix, t.Sep, name)
+}
+
+// structPrefix is the prefix of fields in a nested struct, inline structs keep the parent's.
+func (p Payload) structPrefix(t tag) string {
+	if p.inline(t) {
+		return p.Prefix
+	}
+	return p.envName(t, t.Name)
+}
+
+func joinName(prefix, sep, name string) string {
+	if prefix == "" {
+		return name
+	}
+	if name == "" {
+		return prefix
+	}
+	return fmt.Sprintf("%s%s%s", prefix, sep, name)
+}
+
+// valueFile returns the file named by NAME_FILE when NAME is absent.
+func valueFile(p Payload, t tag) string {
+	if !p.Opt.Enable(OptEnv) || !(p.Opt.Enable(OptFile) || t.File) {
+		return ""
+	}
+	envName := p.envName(t, t.Name)
+	if _, exist := p.Source.Lookup(envName); exist {
+		return ""
+	}
+	filename, _ := p.Source.Lookup(envName + "_FILE")
+	return filename
 }
 
 func parseValue(p Payload) (string, error) {
-	var value string
+	value, _, err := lookupValue(p)
+	return value, err
+}
+
+// lookupValue also reports whether the env is set, even to an empty string.
+// defaults apply only to an empty field, and with OptOverride an env set to
+// empty string is kept empty instead of falling back to defaults.
+func lookupValue(p Payload) (string, bool, error) {
+	var value, from string
+	var exist bool
+	t := p.tag()
+	empty := isEmpty(p.Field)
 	if p.Opt.Enable(OptEnv) {
-		envName := strings.ToUpper(p.StructField.Name)
-		envDefault := ""
-		envRequire := false
-		sep := "_"
-		envStr, exist := p.StructField.Tag.Lookup("env")
-		if exist {
-			for index, str := range strings.Split(envStr, ",") {
-				if index == 0 && str != "" {
-					envName = str
-				} else if strings.Contains(str, "require") {
-					envRequire = true
-				} else if strings.Contains(str, "default=") {
-					envDefault = strings.TrimPrefix(str, "default=")
-				} else if strings.Contains(str, "sep=") {
-					sep = strings.TrimPrefix(str, "sep=")
-				} else if strings.Contains(str, "empty") {
-					envName = ""
-				}
+		var envName string
+		var err error
+		value, exist, envName, err = lookupAliases(p, t)
+		if err != nil {
+			return "", false, err
+		}
+		if filename := valueFile(p, t); filename != "" && !exist {
+			b, err := os.ReadFile(filename)
+			if err != nil {
+				return "", false, fieldError(p, ErrInvalidValue, "", err)
 			}
+			value, exist = strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r"), true
+		}
+		if exist {
+			markFound(p.found)
+		}
+		if !exist && t.Require && empty {
+			return "", false, fieldError(p, ErrRequired, "", nil)
+		}
+		from = origin(p.Source, envName)
+	}
+	useDefault := empty && !(exist && p.Opt.Enable(OptOverride))
+	if value == "" && useDefault && p.Opt.Enable(OptEnv) {
+		value, from = t.Default, OriginDefault
+	}
+	if value == "" && useDefault && p.Opt.Enable(OptDefault) {
+		value, from = p.StructField.Tag.Get("default"), OriginDefault
+	}
+	if value != "" && (p.Opt.Enable(OptExpand) || t.Expand) {
+		expanded, err := expand(p.Source, value, t.Require, nil)
+		if _, ok := err.(*unresolvedError); ok {
+			return "", exist, fieldError(p, ErrRequired, value, err)
+		}
+		if err != nil {
+			return "", exist, fieldError(p, ErrInvalidValue, value, err)
+		}
+		value = expanded
+	}
+	if (value != "" || exist) && p.Origins != nil {
+		p.Origins[p.path] = from
+	}
+	return value, exist, nil
+}
+
+/*
+`env:"HOST,alias=ADDR|HOSTNAME,deprecated=ADDR"` looks up HOST, ADDR and HOSTNAME
+in order under the same prefix, the first one found supplies the value.
+*/
+func lookupAliases(p Payload, t tag) (value string, exist bool, found string, err error) {
+	primary := p.envName(t, t.Name)
+	found = primary
+	for _, name := range append([]string{t.Name}, t.Aliases...) {
+		envName := p.envName(t, name)
+		v, ok := p.Source.Lookup(envName)
+		if !ok {
+			continue
 		}
-		if p.Prefix != "" {
-			if envName == "" {
-				envName = p.Prefix
-			} else {
-				envName = fmt.Sprintf("%s%s%s", p.Prefix, sep, envName)
+		if !exist {
+			value, exist, found = v, true, envName
+			if t.isDeprecated(name) {
+				p.deprecated(envName, primary)
 			}
+			continue
 		}
-		envValue, exist := os.LookupEnv(envName)
-		if !exist && envRequire {
-			return "", fmt.Errorf("%s require", envName)
+		if v != value {
+			return "", false, found, fieldError(p, ErrInvalidValue, value, fmt.Errorf("%s and %s disagree", found, envName))
 		}
-		if envValue == "" {
-			envValue = envDefault
+	}
+	return value, exist, found, nil
+}
+
+func (t tag) isDeprecated(name string) bool {
+	for _, deprecated := range t.Deprecated {
+		if deprecated == name {
+			return true
 		}
-		value = envValue
 	}
-	if value == "" && p.Opt.Enable(OptDefault) {
-		value = p.StructField.Tag.Get("default")
+	return false
+}
+
+func (p Payload) deprecated(name, replacement string) {
+	if p.OnDeprecated != nil {
+		p.OnDeprecated(name, replacement)
+		return
 	}
-	return value, nil
+	log.Printf("env: %s is deprecated, use %s instead", name, replacement)
 }
 
-func parsePtr(p Payload) error {
-	defer func() {
-		if err := recover(); err != nil {
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// override replaces a pre-populated field with the env value when
+// OptOverride is enabled, an env set to empty string clears the field.
+func override(p Payload) error {
+	if !p.Opt.Enable(OptOverride) {
+		return nil
+	}
+	value, exist, err := lookupValue(p)
+	if err != nil || !exist {
+		return err
+	}
+	if value == "" {
+		p.Field.Set(reflect.Zero(p.Field.Type()))
+		return nil
+	}
+	err = setValue(p, value)
+	if err != nil {
+		return err
+	}
+	return validate(p, value)
+}
+
+// setValue converts value according to the kind of p.Field and stores it.
+func setValue(p Payload, value string) error {
+	if decode := decoderOf(p); decode != nil {
+		return setDecoder(p, decode, value)
+	}
+	switch p.Field.Kind() {
+	case reflect.Ptr:
+		if p.Field.IsNil() {
+			p.Field.Set(reflect.New(p.Field.Type().Elem()))
 		}
-	}()
-	if p.Field.IsNil() {
-		field := reflect.New(p.Field.Type().Elem())
-		p.Field.Set(field)
+		p.Field = p.Field.Elem()
+		return setValue(p, value)
+	case reflect.String:
+		p.Field.SetString(value)
 		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return setInt(p, value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return setUint(p, value)
+	case reflect.Float32, reflect.Float64:
+		return setFloat(p, value)
+	case reflect.Bool:
+		return setBool(p, value)
+	default:
+		return fieldError(p, ErrUnsupportedType, "", nil)
+	}
+}
+
+// decoderOf returns the registered ParserFunc of the field type, or the decode
+// method when its address implements Decoder, encoding.TextUnmarshaler or
+// encoding.BinaryUnmarshaler.
+func decoderOf(p Payload) func(string) error {
+	field := p.Field
+	if parser, ok := p.Parsers[field.Type()]; ok && field.CanSet() {
+		return func(value string) error {
+			v, err := parser(value)
+			if err != nil {
+				return err
+			}
+			rv := reflect.ValueOf(v)
+			if !rv.IsValid() || !rv.Type().AssignableTo(field.Type()) {
+				return fmt.Errorf("parser returns %T, want %v", v, field.Type())
+			}
+			field.Set(rv)
+			return nil
+		}
+	}
+	if decode := timeDecoderOf(p); decode != nil {
+		return decode
+	}
+	if !field.CanAddr() || !field.Addr().CanInterface() {
+		return nil
+	}
+	switch d := field.Addr().Interface().(type) {
+	case Decoder:
+		return d.DecodeEnv
+	case encoding.TextUnmarshaler:
+		return func(value string) error {
+			return d.UnmarshalText([]byte(value))
+		}
+	case encoding.BinaryUnmarshaler:
+		return func(value string) error {
+			return d.UnmarshalBinary([]byte(value))
+		}
 	}
 	return nil
 }
 
 /*
-`env:"field,sep=_,default=df,require,empty"`
+`env:"field,unit=ms"` bare integer of time.Duration is read as milliseconds, default is nanoseconds.
+`env:"field,layout=2006-01-02"` layout of time.Time, default is RFC3339.
+*time.Location loads from a zone name like "Asia/Shanghai".
 */
-func parseStruct(p Payload) error {
-	p.Value = p.Field
-	fieldName := strings.ToUpper(p.StructField.Name)
-	sep := "_"
-	envStr, exist := p.StructField.Tag.Lookup("env")
-	if exist {
-		for index, str := range strings.Split(envStr, ",") {
-			if index == 0 && str != "" {
-				fieldName = str
-			} else if strings.Contains(str, "sep=") {
-				sep = strings.TrimPrefix(str, "sep=")
-			} else if strings.Contains(str, "empty") {
-				fieldName = ""
+func timeDecoderOf(p Payload) func(string) error {
+	field := p.Field
+	if !field.CanSet() {
+		return nil
+	}
+	switch field.Type() {
+	case durationType:
+		return func(value string) error {
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				unit := time.Nanosecond
+				if u := p.tag().Unit; u != "" {
+					d, err := time.ParseDuration("1" + u)
+					if err != nil {
+						return err
+					}
+					unit = d
+				}
+				if unit <= 0 || n > math.MaxInt64/int64(unit) || n < math.MinInt64/int64(unit) {
+					return fmt.Errorf("duration out of range")
+				}
+				field.SetInt(n * int64(unit))
+				return nil
+			}
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return err
 			}
+			field.SetInt(int64(d))
+			return nil
+		}
+	case timeType:
+		return func(value string) error {
+			layout := time.RFC3339
+			if l := p.tag().Layout; l != "" {
+				layout = l
+				if named, ok := layouts[l]; ok {
+					layout = named
+				}
+			}
+			t, err := time.Parse(layout, value)
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.ValueOf(t))
+			return nil
+		}
+	case locationType:
+		return func(value string) error {
+			loc, err := time.LoadLocation(value)
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.ValueOf(loc))
+			return nil
+		}
+	}
+	return nil
+}
+
+func parseDecoder(p Payload, decode func(string) error) error {
+	if !p.Field.IsZero() {
+		return override(p)
+	}
+	value, err := parseValue(p)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return nil
+	}
+	return setDecoder(p, decode, value)
+}
+
+func setDecoder(p Payload, decode func(string) error, value string) error {
+	if err := decode(value); err != nil {
+		return fieldError(p, ErrInvalidValue, value, err)
+	}
+	return nil
+}
+
+/*
+`env:"field,optional"` leave the pointer nil when none of its env is set,
+errors like require of the absent section are dropped with it.
+*/
+func parsePtr(p Payload) error {
+	ptr := p.Field
+	if !ptr.IsNil() {
+		if p.addrs[addr{ptr.Pointer(), ptr.Type().Elem()}] {
+			return nil
 		}
+		p.Field = ptr.Elem()
+		return parseField(p)
 	}
-	if p.Prefix == "" {
-		p.Prefix = fieldName
-		return parse(p)
+	if !ptr.CanSet() || p.visiting[ptr.Type().Elem()] {
+		return nil
 	}
-	if fieldName == "" {
-		return parse(p)
+	field := reflect.New(ptr.Type().Elem())
+	p.Field = field.Elem()
+	if !p.tag().Optional {
+		ptr.Set(field)
+		return parseField(p)
 	}
-	p.Prefix = fmt.Sprintf("%s%s%s", p.Prefix, sep, fieldName)
+	outer, found := p.found, 0
+	p.found = &found
+	opt := p.Opt
+	p.Opt |= OptCollect
+	err := parseField(p)
+	if found == 0 {
+		return nil
+	}
+	markFound(outer)
+	ptr.Set(field)
+	if errs, ok := err.(*Errors); ok && !opt.Enable(OptCollect) {
+		return errs.Errors[0]
+	}
+	return err
+}
+
+func parseStruct(p Payload) error {
+	p.Value = p.Field
+	p.Prefix = p.structPrefix(p.tag())
 	return parse(p)
 }
 
 func parseString(p Payload) error {
 	if p.Field.String() != "" {
-		return nil
+		return override(p)
 	}
 	value, err := parseValue(p)
 	if err != nil {
 		return err
 	}
 	if value == "" {
-		return nil
+		return checkEmpty(p)
 	}
 	p.Field.SetString(value)
-	return nil
+	return validate(p, value)
 }
 
 func parseInt(p Payload) error {
 	if p.Field.Int() != 0 {
-		return nil
+		return override(p)
 	}
 	value, err := parseValue(p)
 	if err != nil {
 		return err
 	}
 	if value == "" {
-		return nil
+		return checkEmpty(p)
 	}
+	err = setInt(p, value)
+	if err != nil {
+		return err
+	}
+	return validate(p, value)
+}
+
+func setInt(p Payload, value string) error {
 	iv, err := strconv.Atoi(value)
 	if err != nil {
-		return fmt.Errorf("%s invalid [%s]", p.StructField.Name, value)
+		return fieldError(p, ErrInvalidValue, value, err)
 	}
 	p.Field.SetInt(int64(iv))
 	return nil
@@ -217,18 +849,26 @@ func parseInt(p Payload) error {
 
 func parseUint(p Payload) error {
 	if p.Field.Uint() != 0 {
-		return nil
+		return override(p)
 	}
 	value, err := parseValue(p)
 	if err != nil {
 		return err
 	}
 	if value == "" {
-		return nil
+		return checkEmpty(p)
+	}
+	err = setUint(p, value)
+	if err != nil {
+		return err
 	}
+	return validate(p, value)
+}
+
+func setUint(p Payload, value string) error {
 	iv, err := strconv.ParseUint(value, 0, 64)
 	if err != nil {
-		return fmt.Errorf("%s invalid [%s]", p.StructField.Name, value)
+		return fieldError(p, ErrInvalidValue, value, err)
 	}
 	p.Field.SetUint(iv)
 	return nil
@@ -236,18 +876,26 @@ func parseUint(p Payload) error {
 
 func parseFloat(p Payload) error {
 	if p.Field.Float() != 0 {
-		return nil
+		return override(p)
 	}
 	value, err := parseValue(p)
 	if err != nil {
 		return err
 	}
 	if value == "" {
-		return nil
+		return checkEmpty(p)
 	}
+	err = setFloat(p, value)
+	if err != nil {
+		return err
+	}
+	return validate(p, value)
+}
+
+func setFloat(p Payload, value string) error {
 	iv, err := strconv.ParseFloat(value, 64)
 	if err != nil {
-		return fmt.Errorf("%s invalid [%s]", p.StructField.Name, value)
+		return fieldError(p, ErrInvalidValue, value, err)
 	}
 	p.Field.SetFloat(iv)
 	return nil
@@ -260,35 +908,298 @@ func parseChan(p Payload) error {
 	return nil
 }
 
+/*
+`env:"field,delim=;,kvsep=:"`
+*/
 func parseMap(p Payload) error {
-	defer func() {
-		if err := recover(); err != nil {
+	if p.Field.Len() != 0 {
+		return nil
+	}
+	t := p.tag()
+	if t.PrefixMap && p.Opt.Enable(OptEnv) {
+		return parsePrefixMap(p, t)
+	}
+	value, err := parseValue(p)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		if p.Field.IsNil() {
+			p.Field.Set(reflect.MakeMap(p.Field.Type()))
 		}
-	}()
-	if p.Field.IsNil() {
-		p.Field.Set(reflect.MakeMap(p.Field.Type()))
+		return nil
+	}
+	m := reflect.MakeMap(p.Field.Type())
+	path := p.path
+	for _, pair := range strings.Split(value, t.Delim) {
+		kv := strings.SplitN(pair, t.KVSep, 2)
+		if len(kv) != 2 {
+			return fieldError(p, ErrInvalidValue, value, fmt.Errorf("invalid pair [%s]", pair))
+		}
+		k := reflect.New(p.Field.Type().Key()).Elem()
+		ep := p.withField(k)
+		ep.path = fmt.Sprintf("%s[%s]", path, strings.TrimSpace(kv[0]))
+		err = setValue(ep, strings.TrimSpace(kv[0]))
+		if err != nil {
+			return err
+		}
+		v := reflect.New(p.Field.Type().Elem()).Elem()
+		err = setValue(ep.withField(v), strings.TrimSpace(kv[1]))
+		if err != nil {
+			return err
+		}
+		m.SetMapIndex(k, v)
+	}
+	p.Field.Set(m)
+	return nil
+}
+
+/*
+`env:"LABELS,prefixmap"` reads LABELS_TEAM=core, LABELS_TIER=gold as {"TEAM": "core", "TIER": "gold"}.
+struct values take the key up to the next sep, LABELS_A_ADDR is parsed into m["A"].Addr.
+*/
+func parsePrefixMap(p Payload, t tag) error {
+	prefix := p.envName(t, t.Name) + t.Sep
+	keyType := p.Field.Type().Key()
+	elemType := p.Field.Type().Elem()
+	m := reflect.MakeMap(p.Field.Type())
+	path := p.path
+	for _, kv := range environ(p.Source) {
+		if !strings.HasPrefix(kv, prefix) {
+			continue
+		}
+		kv = strings.TrimPrefix(kv, prefix)
+		index := strings.Index(kv, "=")
+		if index <= 0 {
+			continue
+		}
+		key, value := kv[:index], kv[index+1:]
+		if isStruct(elemType) {
+			if index = strings.Index(key, t.Sep); index > 0 {
+				key = key[:index]
+			}
+		}
+		k := reflect.New(keyType).Elem()
+		ep := p.withField(k)
+		ep.path = fmt.Sprintf("%s[%s]", path, key)
+		err := setValue(ep, key)
+		if err != nil {
+			return err
+		}
+		if m.MapIndex(k).IsValid() {
+			continue
+		}
+		v := reflect.New(elemType).Elem()
+		if isStruct(elemType) {
+			elem := v
+			if elem.Kind() == reflect.Ptr {
+				elem.Set(reflect.New(elemType.Elem()))
+				elem = elem.Elem()
+			}
+			err = parse(p.withPrefix(elem, prefix+key, ep.path))
+		} else {
+			err = setValue(ep.withField(v), value)
+		}
+		if err != nil {
+			return err
+		}
+		m.SetMapIndex(k, v)
+	}
+	if m.Len() == 0 && t.Require {
+		return fieldError(p, ErrRequired, "", nil)
+	}
+	if m.Len() > 0 {
+		markFound(p.found)
 	}
+	p.Field.Set(m)
 	return nil
 }
 
+/*
+`env:"field,delim=;"`
+*/
 func parseSlice(p Payload) error {
-	defer func() {
-		if err := recover(); err != nil {
+	if p.Field.Len() != 0 {
+		return nil
+	}
+	if isStruct(p.Field.Type().Elem()) && p.Opt.Enable(OptEnv) {
+		return parseStructSlice(p, p.tag())
+	}
+	value, err := parseValue(p)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		if p.Field.IsNil() {
+			p.Field.Set(reflect.MakeSlice(p.Field.Type(), 0, 0))
 		}
-	}()
-	if p.Field.IsNil() {
-		p.Field.Set(reflect.MakeSlice(p.Field.Type(), 0, 0))
+		return nil
+	}
+	items := strings.Split(value, p.tag().Delim)
+	slice := reflect.MakeSlice(p.Field.Type(), len(items), len(items))
+	err = setItems(p, slice, items)
+	if err != nil {
+		return err
+	}
+	p.Field.Set(slice)
+	return nil
+}
+
+func isStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// maxSparseIndex bounds the length of a sparse slice, which comes from the env.
+const maxSparseIndex = 1<<16 - 1
+
+/*
+`env:"SERVERS"` reads SERVERS_0_HOST, SERVERS_1_HOST... into []Server, stops at the first missing index.
+`env:"SERVERS,sparse"` allows gaps, the length is the max index + 1, up to maxSparseIndex + 1.
+*/
+func parseStructSlice(p Payload, t tag) error {
+	prefix := p.envName(t, t.Name) + t.Sep
+	indexes := map[int]bool{}
+	for _, kv := range environ(p.Source) {
+		if !strings.HasPrefix(kv, prefix) {
+			continue
+		}
+		kv = strings.TrimPrefix(kv, prefix)
+		end := 0
+		for end < len(kv) && kv[end] >= '0' && kv[end] <= '9' {
+			end++
+		}
+		index, err := strconv.Atoi(kv[:end])
+		if t.Sparse && end > 0 && (err != nil || index > maxSparseIndex) {
+			return fieldError(p, ErrInvalidValue, kv[:end], fmt.Errorf("index out of range, max [%d]", maxSparseIndex))
+		}
+		if err != nil {
+			continue
+		}
+		indexes[index] = true
+	}
+	length := 0
+	for index := range indexes {
+		if t.Sparse && index >= length {
+			length = index + 1
+		}
+	}
+	if !t.Sparse {
+		for indexes[length] {
+			length++
+		}
+	}
+	if length == 0 {
+		if t.Require {
+			return fieldError(p, ErrRequired, "", nil)
+		}
+		if p.Field.IsNil() {
+			p.Field.Set(reflect.MakeSlice(p.Field.Type(), 0, 0))
+		}
+		return nil
+	}
+	slice := reflect.MakeSlice(p.Field.Type(), length, length)
+	errs := &Errors{}
+	for index := 0; index < length; index++ {
+		elem := slice.Index(index)
+		if elem.Kind() == reflect.Ptr {
+			elem.Set(reflect.New(elem.Type().Elem()))
+			elem = elem.Elem()
+		}
+		err := parse(p.withPrefix(elem, fmt.Sprintf("%s%d", prefix, index), fmt.Sprintf("%s[%d]", p.path, index)))
+		if err == nil {
+			continue
+		}
+		if !p.Opt.Enable(OptCollect) {
+			return err
+		}
+		errs.add(p, err)
 	}
+	if len(errs.Errors) != 0 {
+		return errs
+	}
+	p.Field.Set(slice)
+	return nil
+}
+
+func setItems(p Payload, list reflect.Value, items []string) error {
+	path := p.path
+	for index, item := range items {
+		p.path = fmt.Sprintf("%s[%d]", path, index)
+		err := setValue(p.withField(list.Index(index)), strings.TrimSpace(item))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+`env:"field,delim=;,exact"`
+`env:"field,encoding=hex"` fill [N]byte from hex or base64.
+*/
+func parseArray(p Payload) error {
+	if !p.Field.IsZero() {
+		return nil
+	}
+	value, err := parseValue(p)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return nil
+	}
+	t := p.tag()
+	array := reflect.New(p.Field.Type()).Elem()
+	if t.Encoding != "" && p.Field.Type().Elem().Kind() == reflect.Uint8 {
+		var b []byte
+		switch t.Encoding {
+		case "hex":
+			b, err = hex.DecodeString(value)
+		case "base64":
+			b, err = base64.StdEncoding.DecodeString(value)
+		default:
+			return fieldError(p, ErrUnsupportedType, value, fmt.Errorf("unsupport encoding [%s]", t.Encoding))
+		}
+		if err != nil {
+			return fieldError(p, ErrInvalidValue, value, err)
+		}
+		err = checkArrayLen(p, t, value, len(b))
+		if err != nil {
+			return err
+		}
+		reflect.Copy(array, reflect.ValueOf(b))
+		p.Field.Set(array)
+		return nil
+	}
+	items := strings.Split(value, t.Delim)
+	err = checkArrayLen(p, t, value, len(items))
+	if err != nil {
+		return err
+	}
+	err = setItems(p, array, items)
+	if err != nil {
+		return err
+	}
+	p.Field.Set(array)
 	return nil
 }
 
-func parseArray(_ Payload) error {
+func checkArrayLen(p Payload, t tag, value string, length int) error {
+	if length > p.Field.Len() {
+		return fieldError(p, ErrInvalidValue, value, fmt.Errorf("too many items [%d], max [%d]", length, p.Field.Len()))
+	}
+	if t.Exact && length != p.Field.Len() {
+		return fieldError(p, ErrInvalidValue, value, fmt.Errorf("require [%d] items, got [%d]", p.Field.Len(), length))
+	}
 	return nil
 }
 
 func parseBool(p Payload) error {
 	if p.Field.Bool() {
-		return nil
+		return override(p)
 	}
 	value, err := parseValue(p)
 	if err != nil {
@@ -301,3 +1212,12 @@ func parseBool(p Payload) error {
 	p.Field.SetBool(b)
 	return nil
 }
+
+func setBool(p Payload, value string) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fieldError(p, ErrInvalidValue, value, err)
+	}
+	p.Field.SetBool(b)
+	return nil
+}
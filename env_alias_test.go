@@ -0,0 +1,61 @@
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+type TestAliasParseEnv struct {
+	Postgres TestAliasPostgres `env:"PG"`
+}
+
+type TestAliasPostgres struct {
+	Host string `env:"HOST,alias=ADDR|HOSTNAME,deprecated=ADDR"`
+	Port int    `env:"PORT,alias=P,default=5432"`
+}
+
+func TestAliasParse(t *testing.T) {
+	assert := assertWrap(t)
+	var deprecated [][2]string
+	onDeprecated := func(name, replacement string) {
+		deprecated = append(deprecated, [2]string{name, replacement})
+	}
+	parse := func(source MapEnv) (TestAliasParseEnv, error) {
+		deprecated = nil
+		test := TestAliasParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: source, OnDeprecated: onDeprecated})
+		return test, err
+	}
+	{
+		test, err := parse(MapEnv{"PG_HOST": "host", "PG_P": "1"})
+		assert("TestAliasParse", test.Postgres.Host, "host")
+		assert("TestAliasParse", test.Postgres.Port, 1)
+		assert("TestAliasParse", len(deprecated), 0)
+		assert("TestAliasParse", err, nil)
+	}
+	{
+		test, err := parse(MapEnv{"PG_ADDR": "addr"})
+		assert("TestAliasParse", test.Postgres.Host, "addr")
+		assert("TestAliasParse", test.Postgres.Port, 5432)
+		assert("TestAliasParse", deprecated, [][2]string{{"PG_ADDR", "PG_HOST"}})
+		assert("TestAliasParse", err, nil)
+	}
+	{
+		test, err := parse(MapEnv{"PG_HOSTNAME": "hostname"})
+		assert("TestAliasParse", test.Postgres.Host, "hostname")
+		assert("TestAliasParse", len(deprecated), 0)
+		assert("TestAliasParse", err, nil)
+	}
+	{
+		test, err := parse(MapEnv{"PG_HOST": "same", "PG_ADDR": "same"})
+		assert("TestAliasParse", test.Postgres.Host, "same")
+		assert("TestAliasParse", len(deprecated), 0)
+		assert("TestAliasParse", err, nil)
+	}
+	{
+		test, err := parse(MapEnv{"PG_ADDR": "addr", "PG_HOSTNAME": "hostname"})
+		assert("TestAliasParse", test.Postgres.Host, "")
+		assert("TestAliasParse", errors.Is(err, ErrInvalidValue), true)
+		assert("TestAliasParse", err.Error(), "Postgres.Host invalid [addr]: PG_ADDR and PG_HOSTNAME disagree")
+	}
+}
@@ -0,0 +1,79 @@
+package env
+
+import (
+	"os"
+	"testing"
+)
+
+type TestArrayParseEnv struct {
+	Vector   [3]float64 `env:"TEST_ARRAY_VECTOR,shared"`
+	Short    [3]int     `env:"TEST_ARRAY_SHORT,delim=;"`
+	Hex      [4]byte    `env:"TEST_ARRAY_HEX,encoding=hex"`
+	Base64   [4]byte    `env:"TEST_ARRAY_BASE64,encoding=base64"`
+	Bytes    [2]byte    `env:"TEST_ARRAY_BYTES"`
+	NotEmpty [2]int     `env:"TEST_ARRAY_VECTOR,shared"`
+	Empty    [2]int
+}
+
+type TestArrayParseTooMany struct {
+	Vector [2]int `env:"TEST_ARRAY_TOO_MANY"`
+}
+
+type TestArrayParseExact struct {
+	Vector [3]int `env:"TEST_ARRAY_EXACT,exact"`
+}
+
+type TestArrayParseExactHex struct {
+	Key [4]byte `env:"TEST_ARRAY_EXACT_HEX,exact,encoding=hex"`
+}
+
+type TestArrayParseInvalid struct {
+	Vector [2]int `env:"TEST_ARRAY_INVALID"`
+}
+
+func TestArrayParse(t *testing.T) {
+	assert := assertWrap(t)
+	_ = os.Setenv("TEST_ARRAY_VECTOR", "1,2.5,3")
+	_ = os.Setenv("TEST_ARRAY_SHORT", "1;2")
+	_ = os.Setenv("TEST_ARRAY_HEX", "deadbeef")
+	_ = os.Setenv("TEST_ARRAY_BASE64", "AQIDBA==")
+	_ = os.Setenv("TEST_ARRAY_BYTES", "1,255")
+	_ = os.Setenv("TEST_ARRAY_TOO_MANY", "1,2,3")
+	_ = os.Setenv("TEST_ARRAY_EXACT", "1,2")
+	_ = os.Setenv("TEST_ARRAY_EXACT_HEX", "dead")
+	_ = os.Setenv("TEST_ARRAY_INVALID", "1,xxx")
+	{
+		test := TestArrayParseEnv{NotEmpty: [2]int{0, 1}}
+		err := Parse(&test)
+		assert("TestArrayParse", test.Vector, [3]float64{1, 2.5, 3})
+		assert("TestArrayParse", test.Short, [3]int{1, 2, 0})
+		assert("TestArrayParse", test.Hex, [4]byte{0xde, 0xad, 0xbe, 0xef})
+		assert("TestArrayParse", test.Base64, [4]byte{1, 2, 3, 4})
+		assert("TestArrayParse", test.Bytes, [2]byte{1, 255})
+		assert("TestArrayParse", test.NotEmpty, [2]int{0, 1})
+		assert("TestArrayParse", test.Empty, [2]int{})
+		assert("TestArrayParse", err, nil)
+	}
+	{
+		test := TestArrayParseTooMany{}
+		err := Parse(&test)
+		assert("TestArrayParse", err.Error(), "Vector invalid [1,2,3]: too many items [3], max [2]")
+	}
+	{
+		test := TestArrayParseExact{}
+		err := Parse(&test)
+		assert("TestArrayParse", test.Vector, [3]int{})
+		assert("TestArrayParse", err.Error(), "Vector invalid [1,2]: require [3] items, got [2]")
+	}
+	{
+		test := TestArrayParseExactHex{}
+		err := Parse(&test)
+		assert("TestArrayParse", err.Error(), "Key invalid [dead]: require [4] items, got [2]")
+	}
+	{
+		test := TestArrayParseInvalid{}
+		err := Parse(&test)
+		assert("TestArrayParse", test.Vector, [2]int{})
+		assert("TestArrayParse", err.Error(), "Vector[1] invalid [xxx]")
+	}
+}
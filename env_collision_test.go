@@ -0,0 +1,87 @@
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+type TestCollisionParseEnv struct {
+	RPC TestCollisionRPC `env:"RPC"`
+}
+
+type TestCollisionRPC struct {
+	UserName string `env:"USER_NAME"`
+	User     struct {
+		Name string
+	}
+}
+
+type TestCollisionParseShared struct {
+	Server struct {
+		TZ string `env:"/TZ,shared"`
+	}
+	TZ string `env:"TZ,shared"`
+}
+
+type TestCollisionParseOneShared struct {
+	Addr string `env:"ADDR,shared"`
+	Host string `env:"ADDR"`
+}
+
+type TestCollisionParseAlias struct {
+	Host string `env:"HOST,alias=ADDR"`
+	Addr string
+}
+
+type TestCollisionParseAliasShared struct {
+	Host string `env:"HOST,alias=ADDR,shared"`
+	Addr string `env:",shared"`
+}
+
+func TestCollisionParse(t *testing.T) {
+	assert := assertWrap(t)
+	{
+		test := TestCollisionParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"RPC_USER_NAME": "rpc"}})
+		assert("TestCollisionParse", err.Error(), "RPC.User.Name collision [RPC_USER_NAME]: also read by RPC.UserName")
+		assert("TestCollisionParse", errors.Is(err, ErrCollision), true)
+		assert("TestCollisionParse", test.RPC.UserName, "")
+		var fieldErr *FieldError
+		assert("TestCollisionParse", errors.As(err, &fieldErr), true)
+		assert("TestCollisionParse", fieldErr.Path, "RPC.User.Name")
+		assert("TestCollisionParse", fieldErr.EnvName, "RPC_USER_NAME")
+	}
+	{
+		test := TestCollisionParseShared{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"TZ": "UTC"}})
+		assert("TestCollisionParse", err, nil)
+		assert("TestCollisionParse", test.Server.TZ, "UTC")
+		assert("TestCollisionParse", test.TZ, "UTC")
+	}
+	{
+		test := TestCollisionParseOneShared{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptCollect, Source: MapEnv{}})
+		assert("TestCollisionParse", err.Error(), "Host collision [ADDR]: also read by Addr")
+		var errs *Errors
+		assert("TestCollisionParse", errors.As(err, &errs), true)
+		assert("TestCollisionParse", len(errs.Errors), 1)
+	}
+	{
+		test := TestCollisionParseOneShared{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptSilent, Source: MapEnv{"ADDR": "addr"}})
+		assert("TestCollisionParse", err, nil)
+		assert("TestCollisionParse", test.Host, "addr")
+	}
+	{
+		test := TestCollisionParseAlias{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"ADDR": "x"}})
+		assert("TestCollisionParse", err.Error(), "Addr collision [ADDR]: also read by Host")
+		assert("TestCollisionParse", test, TestCollisionParseAlias{})
+	}
+	{
+		test := TestCollisionParseAliasShared{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"ADDR": "x"}})
+		assert("TestCollisionParse", err, nil)
+		assert("TestCollisionParse", test, TestCollisionParseAliasShared{Host: "x", Addr: "x"})
+	}
+}
@@ -0,0 +1,69 @@
+package env
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+)
+
+type TestLevel int
+
+func (l *TestLevel) DecodeEnv(value string) error {
+	switch value {
+	case "debug":
+		*l = 1
+	case "info":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %s", value)
+	}
+	return nil
+}
+
+type TestDecoderParseEnv struct {
+	IP       net.IP      `env:"TEST_DECODER_IP"`
+	IPs      []net.IP    `env:"TEST_DECODER_IPS"`
+	Big      *big.Int    `env:"TEST_DECODER_BIG"`
+	URL      url.URL     `env:"TEST_DECODER_URL"`
+	Level    TestLevel   `env:"TEST_DECODER_LEVEL,shared"`
+	Levels   []TestLevel `env:"TEST_DECODER_LEVELS"`
+	Default  TestLevel   `env:"TEST_DECODER_DEFAULT,default=info"`
+	NotEmpty TestLevel   `env:"TEST_DECODER_LEVEL,shared"`
+}
+
+type TestDecoderParseInvalid struct {
+	Level TestLevel `env:"TEST_DECODER_INVALID"`
+}
+
+func TestDecoderParse(t *testing.T) {
+	assert := assertWrap(t)
+	_ = os.Setenv("TEST_DECODER_IP", "127.0.0.1")
+	_ = os.Setenv("TEST_DECODER_IPS", "127.0.0.1,::1")
+	_ = os.Setenv("TEST_DECODER_BIG", "123456789012345678901234567890")
+	_ = os.Setenv("TEST_DECODER_URL", "https://example.com/path")
+	_ = os.Setenv("TEST_DECODER_LEVEL", "debug")
+	_ = os.Setenv("TEST_DECODER_LEVELS", "debug,info")
+	_ = os.Setenv("TEST_DECODER_INVALID", "xxx")
+	{
+		test := TestDecoderParseEnv{NotEmpty: 2}
+		err := Parse(&test)
+		assert("TestDecoderParse", test.IP.String(), "127.0.0.1")
+		assert("TestDecoderParse", len(test.IPs), 2)
+		assert("TestDecoderParse", test.IPs[1].String(), "::1")
+		assert("TestDecoderParse", test.Big.String(), "123456789012345678901234567890")
+		assert("TestDecoderParse", test.URL.Host, "example.com")
+		assert("TestDecoderParse", test.Level, TestLevel(1))
+		assert("TestDecoderParse", test.Levels, []TestLevel{1, 2})
+		assert("TestDecoderParse", test.Default, TestLevel(2))
+		assert("TestDecoderParse", test.NotEmpty, TestLevel(2))
+		assert("TestDecoderParse", err, nil)
+	}
+	{
+		test := TestDecoderParseInvalid{}
+		err := Parse(&test)
+		assert("TestDecoderParse", err.Error(), "Level invalid [xxx]: unknown level xxx")
+	}
+}
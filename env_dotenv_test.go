@@ -0,0 +1,79 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDotenv = `# comment
+export TEST_DOTENV_ADDR=localhost:5432 # inline comment
+TEST_DOTENV_USER = 'post${gres}'
+TEST_DOTENV_PASSWORD="line1\nline2\t\"quoted\" \$HOME"
+TEST_DOTENV_CERT="-----BEGIN-----
+abc
+-----END-----"
+TEST_DOTENV_URL=postgres://${TEST_DOTENV_USER}@$TEST_DOTENV_ADDR/app
+TEST_DOTENV_HASH=a#b
+TEST_DOTENV_EMPTY=
+TEST_DOTENV_OS=${TEST_DOTENV_FROM_OS}
+`
+
+type TestDotenvParseEnv struct {
+	Addr     string `env:"ADDR"`
+	User     string `env:"USER"`
+	Password string `env:"PASSWORD"`
+	Cert     string `env:"CERT"`
+	URL      string `env:"URL"`
+	Hash     string `env:"HASH"`
+	Empty    string `env:"EMPTY,default=empty"`
+	OS       string `env:"OS"`
+}
+
+func TestDotenvParse(t *testing.T) {
+	assert := assertWrap(t)
+	_ = os.Setenv("TEST_DOTENV_FROM_OS", "os")
+	{
+		m, err := ReadDotenv(strings.NewReader(testDotenv))
+		assert("TestDotenvParse", err, nil)
+		test := TestDotenvParseEnv{}
+		err = ParseEntity(Entity{Value: &test, Prefix: "TEST_DOTENV", Opt: OptEnv, Source: m})
+		assert("TestDotenvParse", test.Addr, "localhost:5432")
+		assert("TestDotenvParse", test.User, "post${gres}")
+		assert("TestDotenvParse", test.Password, "line1\nline2\t\"quoted\" $HOME")
+		assert("TestDotenvParse", test.Cert, "-----BEGIN-----\nabc\n-----END-----")
+		assert("TestDotenvParse", test.URL, "postgres://post${gres}@localhost:5432/app")
+		assert("TestDotenvParse", test.Hash, "a#b")
+		assert("TestDotenvParse", test.Empty, "empty")
+		assert("TestDotenvParse", test.OS, "os")
+		assert("TestDotenvParse", err, nil)
+	}
+	{
+		for content, line := range map[string]int{
+			"A=1\nB":              2,
+			"A=1\n=2":             2,
+			"A='1\n\nB=2":         1,
+			"A=1\nB=\"2\n":        2,
+			"A=\"1\" x":           1,
+			"A=1\n\nB=${C":        3,
+			"A=1\n\nB=\"${C\n}\"": 3,
+		} {
+			_, err := ReadDotenv(strings.NewReader(content))
+			var syntaxErr *SyntaxError
+			assert("TestDotenvParse", errors.As(err, &syntaxErr), true)
+			assert("TestDotenvParse", syntaxErr.Line, line)
+		}
+	}
+	{
+		filename := filepath.Join(t.TempDir(), ".env")
+		_ = os.WriteFile(filename, []byte("TEST_DOTENV_LOAD=load\nTEST_DOTENV_FROM_OS=file\n"), 0600)
+		err := LoadDotenv(filename)
+		assert("TestDotenvParse", err, nil)
+		assert("TestDotenvParse", os.Getenv("TEST_DOTENV_LOAD"), "load")
+		assert("TestDotenvParse", os.Getenv("TEST_DOTENV_FROM_OS"), "os")
+		_, err = ReadDotenvFile(filepath.Join(t.TempDir(), "missing"))
+		assert("TestDotenvParse", os.IsNotExist(err), true)
+	}
+}
@@ -0,0 +1,90 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type TestErrorsParseEnv struct {
+	Port    int                     `env:"TEST_ERRORS_PORT"`
+	Name    string                  `env:"TEST_ERRORS_NAME,require"`
+	Nested  TestErrorsParseNested   `env:"TEST_ERRORS_NESTED"`
+	Servers []TestErrorsParseNested `env:"TEST_ERRORS_SERVERS"`
+	Valid   int                     `env:"TEST_ERRORS_VALID"`
+}
+
+type TestErrorsParseNested struct {
+	Ratio float64
+}
+
+func TestErrorsParse(t *testing.T) {
+	assert := assertWrap(t)
+	_ = os.Setenv("TEST_ERRORS_PORT", "xxx")
+	_ = os.Setenv("TEST_ERRORS_NESTED_RATIO", "yyy")
+	_ = os.Setenv("TEST_ERRORS_SERVERS_0_RATIO", "zzz")
+	_ = os.Setenv("TEST_ERRORS_SERVERS_1_RATIO", "1")
+	_ = os.Setenv("TEST_ERRORS_VALID", "1")
+	{
+		test := TestErrorsParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptCollect})
+		assert("TestErrorsParse", test.Valid, 1)
+		var errs *Errors
+		assert("TestErrorsParse", errors.As(err, &errs), true)
+		assert("TestErrorsParse", len(errs.Errors), 4)
+		assert("TestErrorsParse", errs.Errors[0].Path, "Port")
+		assert("TestErrorsParse", errs.Errors[0].EnvName, "TEST_ERRORS_PORT")
+		assert("TestErrorsParse", errs.Errors[0].Value, "xxx")
+		assert("TestErrorsParse", errs.Errors[0].Kind, reflect.Int)
+		assert("TestErrorsParse", errs.Errors[0].Err, ErrInvalidValue)
+		assert("TestErrorsParse", errs.Errors[1].Path, "Name")
+		assert("TestErrorsParse", errs.Errors[2].Path, "Nested.Ratio")
+		assert("TestErrorsParse", errs.Errors[2].EnvName, "TEST_ERRORS_NESTED_RATIO")
+		assert("TestErrorsParse", errs.Errors[3].Path, "Servers[0].Ratio")
+		assert("TestErrorsParse", errs.Errors[3].EnvName, "TEST_ERRORS_SERVERS_0_RATIO")
+		assert("TestErrorsParse", err.Error(), "Port invalid [xxx]; "+
+			"TEST_ERRORS_NAME require; "+
+			"Nested.Ratio invalid [yyy]; "+
+			"Servers[0].Ratio invalid [zzz]")
+		var fieldErr *FieldError
+		assert("TestErrorsParse", errors.As(err, &fieldErr), true)
+		assert("TestErrorsParse", fieldErr.Path, "Port")
+		assert("TestErrorsParse", errors.Is(err, ErrRequired), true)
+		assert("TestErrorsParse", errors.Is(err, ErrInvalidValue), true)
+		assert("TestErrorsParse", errors.Is(err, ErrUnsupportedType), false)
+	}
+	{
+		test := TestErrorsParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv})
+		var fieldErr *FieldError
+		assert("TestErrorsParse", errors.As(err, &fieldErr), true)
+		assert("TestErrorsParse", errors.Is(err, ErrInvalidValue), true)
+		assert("TestErrorsParse", errors.Is(err, strconv.ErrSyntax), true)
+		var numErr *strconv.NumError
+		assert("TestErrorsParse", errors.As(err, &numErr), true)
+		assert("TestErrorsParse", numErr.Num, "xxx")
+		assert("TestErrorsParse", err.Error(), "Port invalid [xxx]")
+	}
+	{
+		test := struct {
+			On bool
+		}{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"ON": "yes"}})
+		assert("TestErrorsParse", test.On, false)
+		assert("TestErrorsParse", err, nil)
+	}
+	{
+		test := TestNotSupportType{}
+		err := Parse(&test)
+		assert("TestErrorsParse", errors.Is(err, ErrUnsupportedType), true)
+		assert("TestErrorsParse", errors.Is(err, ErrInvalidValue), false)
+	}
+	{
+		test := TestErrorsParseNested{}
+		_ = os.Setenv("RATIO", "1")
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptCollect})
+		assert("TestErrorsParse", err, nil)
+	}
+}
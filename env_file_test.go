@@ -0,0 +1,70 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type TestFileParseEnv struct {
+	Password string `env:"PG_PASSWORD,file"`
+	Token    string `env:"TOKEN,file"`
+	Plain    string `env:"PLAIN"`
+	Override string `env:"OVERRIDE,file"`
+}
+
+type TestFileParseInvalid struct {
+	Port int `env:"PORT,file"`
+}
+
+func TestFileParse(t *testing.T) {
+	assert := assertWrap(t)
+	dir := t.TempDir()
+	password := filepath.Join(dir, "pg")
+	token := filepath.Join(dir, "token")
+	port := filepath.Join(dir, "port")
+	_ = os.WriteFile(password, []byte("secret\n"), 0600)
+	_ = os.WriteFile(token, []byte("line1\nline2\r\n"), 0600)
+	_ = os.WriteFile(port, []byte("s3cr3t"), 0600)
+	source := MapEnv{
+		"PG_PASSWORD_FILE": password,
+		"TOKEN_FILE":       token,
+		"PLAIN_FILE":       password,
+		"OVERRIDE":         "env",
+		"OVERRIDE_FILE":    password,
+	}
+	{
+		test := TestFileParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: source})
+		assert("TestFileParse", test.Password, "secret")
+		assert("TestFileParse", test.Token, "line1\nline2")
+		assert("TestFileParse", test.Plain, "")
+		assert("TestFileParse", test.Override, "env")
+		assert("TestFileParse", err, nil)
+	}
+	{
+		test := TestFileParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptFile, Source: source})
+		assert("TestFileParse", test.Plain, "secret")
+		assert("TestFileParse", err, nil)
+	}
+	{
+		test := TestFileParseInvalid{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"PORT_FILE": port}})
+		var fieldErr *FieldError
+		assert("TestFileParse", errors.As(err, &fieldErr), true)
+		assert("TestFileParse", fieldErr.File, port)
+		assert("TestFileParse", fieldErr.Value, "")
+		assert("TestFileParse", err.Error(), "Port invalid file ["+port+"]")
+		assert("TestFileParse", strings.Contains(err.Error(), "s3cr3t"), false)
+	}
+	{
+		missing := filepath.Join(dir, "missing")
+		test := TestFileParseInvalid{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"PORT_FILE": missing}})
+		assert("TestFileParse", errors.Is(err, os.ErrNotExist), true)
+		assert("TestFileParse", strings.HasPrefix(err.Error(), "Port invalid file ["+missing+"]: open "+missing), true)
+	}
+}
@@ -0,0 +1,89 @@
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+type TestInlineUser struct {
+	Name string
+	Addr string
+}
+
+type TestInlineTLS struct {
+	Cert string
+}
+
+type TestInlineParseEnv struct {
+	TestInlineUser
+	*TestInlineTLS
+	Addr string
+}
+
+type TestInlineParseTag struct {
+	TestInlineUser `env:",squash"`
+	TestInlineTLS  `env:"TLS"`
+}
+
+type TestInlineParseNamed struct {
+	TestInlineUser `env:"USER"`
+}
+
+type TestInlineParseConflict struct {
+	TestInlineUser
+	TestInlineOther
+}
+
+type TestInlineOther struct {
+	Name string
+}
+
+func TestInlineParse(t *testing.T) {
+	assert := assertWrap(t)
+	source := MapEnv{
+		"RPC_NAME":                 "name",
+		"RPC_ADDR":                 "addr",
+		"RPC_CERT":                 "cert",
+		"RPC_TESTINLINEUSER_NAME":  "embedded",
+		"RPC_TLS_CERT":             "tls",
+		"RPC_USER_NAME":            "user",
+		"RPC_TESTINLINETLS_CERT":   "embedded",
+		"RPC_TESTINLINEUSER_ADDR":  "embedded",
+		"RPC_TESTINLINEOTHER_NAME": "embedded",
+	}
+	{
+		test := TestInlineParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Prefix: "RPC", Opt: OptEnv, Source: source})
+		assert("TestInlineParse", err, nil)
+		assert("TestInlineParse", test.Name, "embedded")
+		assert("TestInlineParse", test.TestInlineTLS.Cert, "embedded")
+	}
+	{
+		test := TestInlineParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Prefix: "RPC", Opt: OptEnv | OptInline, Source: source})
+		assert("TestInlineParse", err, nil)
+		assert("TestInlineParse", test.Name, "name")
+		assert("TestInlineParse", test.Cert, "cert")
+		assert("TestInlineParse", test.Addr, "addr")
+		assert("TestInlineParse", test.TestInlineUser.Addr, "")
+	}
+	{
+		test := TestInlineParseTag{}
+		err := ParseEntity(Entity{Value: &test, Prefix: "RPC", Opt: OptEnv, Source: source})
+		assert("TestInlineParse", err, nil)
+		assert("TestInlineParse", test.Name, "name")
+		assert("TestInlineParse", test.Cert, "tls")
+	}
+	{
+		test := TestInlineParseNamed{}
+		err := ParseEntity(Entity{Value: &test, Prefix: "RPC", Opt: OptEnv | OptInline, Source: source})
+		assert("TestInlineParse", err, nil)
+		assert("TestInlineParse", test.Name, "user")
+	}
+	{
+		test := TestInlineParseConflict{}
+		err := ParseEntity(Entity{Value: &test, Prefix: "RPC", Opt: OptEnv | OptInline, Source: source})
+		assert("TestInlineParse", err.Error(), "TestInlineOther.Name collision [RPC_NAME]: also read by TestInlineUser.Name")
+		assert("TestInlineParse", errors.Is(err, ErrCollision), true)
+	}
+}
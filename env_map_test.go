@@ -0,0 +1,131 @@
+package env
+
+import (
+	"os"
+	"testing"
+)
+
+type TestMapParseEnv struct {
+	Labels   map[string]string `env:"TEST_MAP_LABELS"`
+	Weights  map[string]int    `env:"TEST_MAP_WEIGHTS,delim=;,kvsep=:"`
+	Ratios   map[int]float64   `env:"TEST_MAP_RATIOS"`
+	Flags    map[string]*bool  `env:"TEST_MAP_FLAGS"`
+	Default  map[string]string `default:"a=1"`
+	Empty    map[string]string
+	NotEmpty map[string]string
+}
+
+type TestMapParseInvalidPair struct {
+	Labels map[string]string `env:"TEST_MAP_INVALID_PAIR"`
+}
+
+type TestMapParseInvalidValue struct {
+	Weights map[string]int `env:"TEST_MAP_INVALID_VALUE"`
+}
+
+type TestMapParseInvalidKey struct {
+	Weights map[int]int `env:"TEST_MAP_INVALID_KEY"`
+}
+
+func TestMapParse(t *testing.T) {
+	assert := assertWrap(t)
+	_ = os.Setenv("TEST_MAP_LABELS", "team=core, tier = gold")
+	_ = os.Setenv("TEST_MAP_WEIGHTS", "a:1;b:2")
+	_ = os.Setenv("TEST_MAP_RATIOS", "1=0.5,2=1.5")
+	_ = os.Setenv("TEST_MAP_FLAGS", "debug=true")
+	_ = os.Setenv("TEST_MAP_INVALID_PAIR", "a=1,b")
+	_ = os.Setenv("TEST_MAP_INVALID_VALUE", "a=1,b=xxx")
+	_ = os.Setenv("TEST_MAP_INVALID_KEY", "xxx=1")
+	{
+		test := TestMapParseEnv{NotEmpty: map[string]string{"x": "y"}}
+		err := Parse(&test)
+		assert("TestMapParse", test.Labels, map[string]string{"team": "core", "tier": "gold"})
+		assert("TestMapParse", test.Weights, map[string]int{"a": 1, "b": 2})
+		assert("TestMapParse", test.Ratios, map[int]float64{1: 0.5, 2: 1.5})
+		assert("TestMapParse", *test.Flags["debug"], true)
+		assert("TestMapParse", test.Default, map[string]string{"a": "1"})
+		assert("TestMapParse", test.Empty, map[string]string{})
+		assert("TestMapParse", test.NotEmpty, map[string]string{"x": "y"})
+		assert("TestMapParse", err, nil)
+	}
+	{
+		test := TestMapParseInvalidPair{}
+		err := Parse(&test)
+		assert("TestMapParse", test.Labels, map[string]string(nil))
+		assert("TestMapParse", err.Error(), "Labels invalid [a=1,b]: invalid pair [b]")
+	}
+	{
+		test := TestMapParseInvalidValue{}
+		err := Parse(&test)
+		assert("TestMapParse", err.Error(), "Weights[b] invalid [xxx]")
+	}
+	{
+		test := TestMapParseInvalidKey{}
+		err := Parse(&test)
+		assert("TestMapParse", err.Error(), "Weights[xxx] invalid [xxx]")
+	}
+}
+
+type TestPrefixMapParseEnv struct {
+	Labels    map[string]string           `env:"TEST_PREFIXMAP_LABELS,prefixmap"`
+	Ports     map[string]int              `env:"TEST_PREFIXMAP_PORTS,prefixmap,sep=."`
+	Upstreams map[string]TestPrefixMapUps `env:"TEST_PREFIXMAP_UPS,prefixmap"`
+	Empty     map[string]string           `env:"TEST_PREFIXMAP_EMPTY,prefixmap"`
+}
+
+type TestPrefixMapUps struct {
+	Addr string
+	Port int `env:",default=80"`
+}
+
+type TestPrefixMapParsePtr struct {
+	Upstreams map[string]*TestPrefixMapUps `env:"UPS,prefixmap"`
+}
+
+type TestPrefixMapParseRequire struct {
+	Labels map[string]string `env:"TEST_PREFIXMAP_REQUIRE,prefixmap,require"`
+}
+
+type TestPrefixMapParseInvalid struct {
+	Ports map[string]int `env:"TEST_PREFIXMAP_INVALID,prefixmap"`
+}
+
+func TestPrefixMapParse(t *testing.T) {
+	assert := assertWrap(t)
+	_ = os.Setenv("TEST_PREFIXMAP_LABELS_TEAM", "core")
+	_ = os.Setenv("TEST_PREFIXMAP_LABELS_TIER", "gold")
+	_ = os.Setenv("TEST_PREFIXMAP_PORTS.HTTP", "80")
+	_ = os.Setenv("TEST_PREFIXMAP_UPS_A_ADDR", "a")
+	_ = os.Setenv("TEST_PREFIXMAP_UPS_B_ADDR", "b")
+	_ = os.Setenv("TEST_PREFIXMAP_UPS_B_PORT", "8080")
+	_ = os.Setenv("TEST_PREFIXMAP_INVALID_HTTP", "xxx")
+	{
+		test := TestPrefixMapParseEnv{}
+		err := Parse(&test)
+		assert("TestPrefixMapParse", test.Labels, map[string]string{"TEAM": "core", "TIER": "gold"})
+		assert("TestPrefixMapParse", test.Ports, map[string]int{"HTTP": 80})
+		assert("TestPrefixMapParse", test.Upstreams, map[string]TestPrefixMapUps{
+			"A": {Addr: "a", Port: 80},
+			"B": {Addr: "b", Port: 8080},
+		})
+		assert("TestPrefixMapParse", test.Empty, map[string]string{})
+		assert("TestPrefixMapParse", err, nil)
+	}
+	{
+		test := TestPrefixMapParsePtr{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"UPS_A_ADDR": "a", "UPS_A_PORT": "8080"}})
+		assert("TestPrefixMapParse", len(test.Upstreams), 1)
+		assert("TestPrefixMapParse", *test.Upstreams["A"], TestPrefixMapUps{Addr: "a", Port: 8080})
+		assert("TestPrefixMapParse", err, nil)
+	}
+	{
+		test := TestPrefixMapParseRequire{}
+		err := Parse(&test)
+		assert("TestPrefixMapParse", err.Error(), "TEST_PREFIXMAP_REQUIRE require")
+	}
+	{
+		test := TestPrefixMapParseInvalid{}
+		err := Parse(&test)
+		assert("TestPrefixMapParse", err.Error(), "Ports[HTTP] invalid [xxx]")
+	}
+}
@@ -0,0 +1,96 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+type TestNamingParseEnv struct {
+	DatabaseURL  string
+	MaxIdleConns int
+	Named        string `env:"CUSTOM"`
+	HTTPServer   TestNamingServer
+}
+
+type TestNamingServer struct {
+	ReadTimeoutMS int
+}
+
+func TestNamingParse(t *testing.T) {
+	assert := assertWrap(t)
+	{
+		for name, words := range map[string]string{
+			"DatabaseURL":    "DATABASE_URL",
+			"MaxIdleConns":   "MAX_IDLE_CONNS",
+			"HTTPServerID":   "HTTP_SERVER_ID",
+			"ID":             "ID",
+			"Base64Data":     "BASE64_DATA",
+			"OAuth2Token":    "O_AUTH2_TOKEN",
+			"XMLHttp2Client": "XML_HTTP2_CLIENT",
+			"IDsecret":       "I_DSECRET",
+			"IDsURL":         "IDS_URL",
+			"APIsKey":        "APIS_KEY",
+			"IPv4Addr":       "IPV4_ADDR",
+			"UserIDs":        "USER_IDS",
+			"URLs":           "URLS",
+			"HTTPSocket":     "HTTP_SOCKET",
+			"snake_Case":     "SNAKE_CASE",
+			"lower":          "LOWER",
+			"ReadTimeoutMS":  "READ_TIMEOUT_MS",
+		} {
+			assert("TestNamingParse", NameUpperSnake(name), words)
+		}
+		assert("TestNamingParse", NameUpper("DatabaseURL"), "DATABASEURL")
+		assert("TestNamingParse", NameLowerSnake("DatabaseURL"), "database_url")
+		assert("TestNamingParse", NameKebab("DatabaseURL"), "database-url")
+		assert("TestNamingParse", NameVerbatim("DatabaseURL"), "DatabaseURL")
+		assert("TestNamingParse", nameSep(NameUpper), "_")
+		assert("TestNamingParse", nameSep(NameKebab), "-")
+		assert("TestNamingParse", nameSep(func(string) string { return "" }), "_")
+	}
+	{
+		test := TestNamingParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, NameMapper: NameUpperSnake, Source: MapEnv{
+			"DATABASE_URL":                "postgres://",
+			"MAX_IDLE_CONNS":              "10",
+			"CUSTOM":                      "custom",
+			"HTTP_SERVER_READ_TIMEOUT_MS": "100",
+		}})
+		assert("TestNamingParse", test.DatabaseURL, "postgres://")
+		assert("TestNamingParse", test.MaxIdleConns, 10)
+		assert("TestNamingParse", test.Named, "custom")
+		assert("TestNamingParse", test.HTTPServer.ReadTimeoutMS, 100)
+		assert("TestNamingParse", err, nil)
+	}
+	{
+		test := TestNamingParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, NameMapper: NameKebab, Source: MapEnv{
+			"database-url":                "postgres://",
+			"http-server-read-timeout-ms": "100",
+		}})
+		assert("TestNamingParse", test.DatabaseURL, "postgres://")
+		assert("TestNamingParse", test.HTTPServer.ReadTimeoutMS, 100)
+		assert("TestNamingParse", err, nil)
+	}
+	{
+		dotted := func(name string) string {
+			return strings.ToLower(strings.Join(splitWords(name), "."))
+		}
+		test := TestNamingParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, NameMapper: dotted, Source: MapEnv{
+			"http.server.read.timeout.ms": "100",
+		}})
+		assert("TestNamingParse", test.HTTPServer.ReadTimeoutMS, 100)
+		assert("TestNamingParse", err, nil)
+	}
+	{
+		test := TestNamingParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{
+			"DATABASEURL":              "postgres://",
+			"HTTPSERVER_READTIMEOUTMS": "100",
+		}})
+		assert("TestNamingParse", test.DatabaseURL, "postgres://")
+		assert("TestNamingParse", test.HTTPServer.ReadTimeoutMS, 100)
+		assert("TestNamingParse", err, nil)
+	}
+}
@@ -0,0 +1,50 @@
+package env
+
+import "testing"
+
+type TestNoPrefixParseEnv struct {
+	Server TestNoPrefixServer `env:"SERVER"`
+	Global TestNoPrefixGlobal `env:"/GLOBAL"`
+}
+
+type TestNoPrefixServer struct {
+	Port  int    `env:"PORT"`
+	Proxy string `env:"/HTTP_PROXY"`
+	TZ    string `env:"TZ,noprefix"`
+	Host  string `env:"/K8S_HOST,alias=KUBERNETES_SERVICE_HOST"`
+}
+
+type TestNoPrefixGlobal struct {
+	Name string `env:"NAME"`
+}
+
+func TestNoPrefixParse(t *testing.T) {
+	assert := assertWrap(t)
+	test := TestNoPrefixParseEnv{}
+	err := ParseEntity(Entity{Value: &test, Prefix: "APP", Opt: OptEnv, Source: MapEnv{
+		"APP_SERVER_PORT":         "80",
+		"APP_SERVER_HTTP_PROXY":   "ignored",
+		"HTTP_PROXY":              "proxy",
+		"TZ":                      "UTC",
+		"KUBERNETES_SERVICE_HOST": "k8s",
+		"GLOBAL_NAME":             "global",
+	}})
+	assert("TestNoPrefixParse", err, nil)
+	assert("TestNoPrefixParse", test.Server.Port, 80)
+	assert("TestNoPrefixParse", test.Server.Proxy, "proxy")
+	assert("TestNoPrefixParse", test.Server.TZ, "UTC")
+	assert("TestNoPrefixParse", test.Server.Host, "k8s")
+	assert("TestNoPrefixParse", test.Global.Name, "global")
+}
+
+func TestNoPrefixError(t *testing.T) {
+	assert := assertWrap(t)
+	type Config struct {
+		Server struct {
+			Proxy string `env:"/HTTP_PROXY,require"`
+		} `env:"SERVER"`
+	}
+	test := Config{}
+	err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{}})
+	assert("TestNoPrefixError", err.Error(), "HTTP_PROXY require")
+}
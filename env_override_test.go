@@ -0,0 +1,83 @@
+package env
+
+import (
+	"testing"
+	"time"
+)
+
+type TestOverrideParseEnv struct {
+	Port    int           `env:"PORT,default=80"`
+	Host    string        `env:"HOST,default=localhost"`
+	Ratio   float64       `env:"RATIO"`
+	Pool    uint          `env:"POOL"`
+	Debug   bool          `env:"DEBUG"`
+	Timeout time.Duration `env:"TIMEOUT"`
+	Name    string        `env:"NAME,require"`
+	Level   string        `env:"LEVEL" default:"info"`
+	Clear   string        `env:"CLEAR,default=df"`
+}
+
+func TestOverrideParse(t *testing.T) {
+	assert := assertWrap(t)
+	source := MapEnv{
+		"PORT":    "9090",
+		"RATIO":   "0.5",
+		"DEBUG":   "false",
+		"TIMEOUT": "1m",
+		"CLEAR":   "",
+	}
+	prefilled := func() TestOverrideParseEnv {
+		return TestOverrideParseEnv{
+			Port:    8080,
+			Host:    "0.0.0.0",
+			Ratio:   1,
+			Pool:    10,
+			Debug:   true,
+			Timeout: time.Second,
+			Name:    "app",
+			Level:   "warn",
+			Clear:   "value",
+		}
+	}
+	{
+		test := prefilled()
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptDefault | OptOverride, Source: source})
+		assert("TestOverrideParse", test.Port, 9090)
+		assert("TestOverrideParse", test.Host, "0.0.0.0")
+		assert("TestOverrideParse", test.Ratio, 0.5)
+		assert("TestOverrideParse", test.Pool, uint(10))
+		assert("TestOverrideParse", test.Debug, false)
+		assert("TestOverrideParse", test.Timeout, time.Minute)
+		assert("TestOverrideParse", test.Name, "app")
+		assert("TestOverrideParse", test.Level, "warn")
+		assert("TestOverrideParse", test.Clear, "")
+		assert("TestOverrideParse", err, nil)
+	}
+	{
+		test := prefilled()
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptDefault, Source: source})
+		assert("TestOverrideParse", test.Port, 8080)
+		assert("TestOverrideParse", test.Debug, true)
+		assert("TestOverrideParse", test.Clear, "value")
+		assert("TestOverrideParse", err, nil)
+	}
+	{
+		test := TestOverrideParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptDefault | OptOverride, Source: MapEnv{"NAME": "app", "CLEAR": ""}})
+		assert("TestOverrideParse", test.Port, 80)
+		assert("TestOverrideParse", test.Level, "info")
+		assert("TestOverrideParse", test.Clear, "")
+		assert("TestOverrideParse", err, nil)
+	}
+	{
+		test := TestOverrideParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptDefault, Source: MapEnv{"NAME": "app", "CLEAR": ""}})
+		assert("TestOverrideParse", test.Clear, "df")
+		assert("TestOverrideParse", err, nil)
+	}
+	{
+		test := TestOverrideParseEnv{Port: 8080}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptOverride, Source: MapEnv{"PORT": "xxx"}})
+		assert("TestOverrideParse", err.Error(), "Port invalid [xxx]")
+	}
+}
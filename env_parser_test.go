@@ -0,0 +1,73 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type TestParserUpper string
+
+type TestParserParseEnv struct {
+	Match   *regexp.Regexp    `env:"TEST_PARSER_MATCH"`
+	Upper   TestParserUpper   `env:"TEST_PARSER_UPPER"`
+	Uppers  []TestParserUpper `env:"TEST_PARSER_UPPERS"`
+	Default TestParserUpper   `env:"TEST_PARSER_DEFAULT,default=df"`
+}
+
+type TestParserParseInvalid struct {
+	Match *regexp.Regexp `env:"TEST_PARSER_INVALID"`
+}
+
+type TestParserParseMismatch struct {
+	Upper TestParserUpper `env:"TEST_PARSER_UPPER"`
+}
+
+func TestParserParse(t *testing.T) {
+	assert := assertWrap(t)
+	_ = os.Setenv("TEST_PARSER_MATCH", "^[a-z]+$")
+	_ = os.Setenv("TEST_PARSER_UPPER", "abc")
+	_ = os.Setenv("TEST_PARSER_UPPERS", "a,b")
+	_ = os.Setenv("TEST_PARSER_INVALID", "[")
+	parsers := map[reflect.Type]ParserFunc{
+		reflect.TypeOf(&regexp.Regexp{}): func(value string) (interface{}, error) {
+			return regexp.Compile(value)
+		},
+		reflect.TypeOf(TestParserUpper("")): func(value string) (interface{}, error) {
+			return TestParserUpper(strings.ToUpper(value)), nil
+		},
+	}
+	{
+		test := TestParserParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptDefault, Parsers: parsers})
+		assert("TestParserParse", test.Match.MatchString("abc"), true)
+		assert("TestParserParse", test.Upper, TestParserUpper("ABC"))
+		assert("TestParserParse", test.Uppers, []TestParserUpper{"A", "B"})
+		assert("TestParserParse", test.Default, TestParserUpper("DF"))
+		assert("TestParserParse", err, nil)
+	}
+	{
+		test := TestParserParseMismatch{}
+		err := Parse(&test)
+		assert("TestParserParse", test.Upper, TestParserUpper("abc"))
+		assert("TestParserParse", err, nil)
+	}
+	{
+		test := TestParserParseInvalid{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Parsers: parsers})
+		assert("TestParserParse", test.Match, (*regexp.Regexp)(nil))
+		assert("TestParserParse", err.Error(), "Match invalid [[]: error parsing regexp: missing closing ]: `[`")
+	}
+	{
+		test := TestParserParseMismatch{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Parsers: map[reflect.Type]ParserFunc{
+			reflect.TypeOf(TestParserUpper("")): func(value string) (interface{}, error) {
+				return value, nil
+			},
+		}})
+		assert("TestParserParse", test.Upper, TestParserUpper(""))
+		assert("TestParserParse", err.Error(), "Upper invalid [abc]: parser returns string, want env.TestParserUpper")
+	}
+}
@@ -1,6 +1,7 @@
 package env
 
 import (
+	"os"
 	"testing"
 )
 
@@ -29,8 +30,59 @@ type TestPtrParseEnv2 struct {
 	B int    `env:",default=2"`
 }
 
+type TestPtrParseOptional struct {
+	Postgres *TestPtrParsePostgres `env:"TEST_PTR_PG,optional"`
+	Redis    *TestPtrParseEnv2     `env:"TEST_PTR_RDS,optional"`
+	Port     *int                  `env:"TEST_PTR_PORT,optional,shared"`
+	Missing  *int                  `env:"TEST_PTR_MISSING,optional"`
+	Existing *int                  `env:"TEST_PTR_PORT,shared"`
+}
+
+type TestPtrParsePostgres struct {
+	Addr string
+	User string `env:",default=postgres"`
+}
+
+type TestPtrParseInvalid struct {
+	Port *int `env:"TEST_PTR_INVALID_PORT"`
+}
+
+type TestPtrParseNode struct {
+	Name string
+	Next *TestPtrParseNode
+}
+
+type TestPtrParseCycle struct {
+	Head TestPtrParseNode
+}
+
+type TestPtrParseRequired struct {
+	Server *struct {
+		Host string
+		Port int `env:",require"`
+	} `env:"TEST_PTR_SERVER,optional"`
+}
+
+type testPtrParseLabels map[string]string
+
+type testPtrParseNames []string
+
+type TestPtrParseEmbedded struct {
+	testPtrParseLabels
+	testPtrParseNames
+	Name string
+}
+
+type TestPtrParseUnexported struct {
+	Section *struct {
+		a string
+		B string
+	} `env:"TEST_PTR_SECTION"`
+}
+
 func TestPtrParse(t *testing.T) {
 	assert := assertWrap(t)
+	_ = os.Setenv("A", "")
 	{
 		test := TestPtrParseNil{}
 		err := Parse(&test)
@@ -52,10 +104,70 @@ func TestPtrParse(t *testing.T) {
 	{
 		test := TestPtrParseEnv{}
 		err := Parse(&test)
-		assert("TestPtrParse", test.TestPtrParseEnv1.A, "")
-		assert("TestPtrParse", test.TestPtrParseEnv1.B, 0)
-		assert("TestPtrParse", test.TestPtrParseEnv2.A, "")
-		assert("TestPtrParse", test.TestPtrParseEnv2.B, 0)
+		assert("TestPtrParse", test.TestPtrParseEnv1.A, "test")
+		assert("TestPtrParse", test.TestPtrParseEnv1.B, 1)
+		assert("TestPtrParse", test.TestPtrParseEnv1.TestPtrParseEnv2.A, "test2")
+		assert("TestPtrParse", test.TestPtrParseEnv2.A, "test2")
+		assert("TestPtrParse", test.TestPtrParseEnv2.B, 2)
+		assert("TestPtrParse", err, nil)
+	}
+	{
+		_ = os.Setenv("TEST_PTR_PG_ADDR", "localhost:5432")
+		_ = os.Setenv("TEST_PTR_PORT", "8080")
+		port := 80
+		test := TestPtrParseOptional{Existing: &port}
+		err := Parse(&test)
+		assert("TestPtrParse", test.Postgres.Addr, "localhost:5432")
+		assert("TestPtrParse", test.Postgres.User, "postgres")
+		assert("TestPtrParse", test.Redis, (*TestPtrParseEnv2)(nil))
+		assert("TestPtrParse", *test.Port, 8080)
+		assert("TestPtrParse", test.Missing, (*int)(nil))
+		assert("TestPtrParse", *test.Existing, 80)
+		assert("TestPtrParse", err, nil)
+	}
+	{
+		_ = os.Setenv("TEST_PTR_INVALID_PORT", "xxx")
+		test := TestPtrParseInvalid{}
+		err := Parse(&test)
+		assert("TestPtrParse", err.Error(), "Port invalid [xxx]")
+	}
+	{
+		test := TestPtrParseCycle{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"HEAD_NAME": "head"}})
+		assert("TestPtrParse", test.Head.Name, "head")
+		assert("TestPtrParse", test.Head.Next, (*TestPtrParseNode)(nil))
+		assert("TestPtrParse", err, nil)
+	}
+	{
+		head := &TestPtrParseNode{Next: &TestPtrParseNode{}}
+		head.Next.Next = head
+		err := ParseEntity(Entity{Value: head, Opt: OptEnv, Source: MapEnv{"NAME": "a", "NEXT_NAME": "b"}})
+		assert("TestPtrParse", head.Name, "a")
+		assert("TestPtrParse", head.Next.Name, "b")
+		assert("TestPtrParse", head.Next.Next == head, true)
+		assert("TestPtrParse", err, nil)
+	}
+	{
+		test := TestPtrParseRequired{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{}})
+		assert("TestPtrParse", test.Server == nil, true)
+		assert("TestPtrParse", err, nil)
+		err = ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"TEST_PTR_SERVER_HOST": "localhost"}})
+		assert("TestPtrParse", test.Server.Host, "localhost")
+		assert("TestPtrParse", err.Error(), "TEST_PTR_SERVER_PORT require")
+	}
+	{
+		test := TestPtrParseUnexported{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"TEST_PTR_SECTION_A": "a", "TEST_PTR_SECTION_B": "b"}})
+		assert("TestPtrParse", test.Section.a, "")
+		assert("TestPtrParse", test.Section.B, "b")
+		assert("TestPtrParse", err, nil)
+	}
+	{
+		test := TestPtrParseEmbedded{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"NAME": "name"}})
+		assert("TestPtrParse", test.Name, "name")
+		assert("TestPtrParse", test.testPtrParseLabels, testPtrParseLabels(nil))
 		assert("TestPtrParse", err, nil)
 	}
 }
@@ -0,0 +1,52 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type TestSecretParseEnv struct {
+	Password Secret `env:"PASSWORD"`
+	Token    string `env:"TOKEN,secret"`
+}
+
+type TestSecretParseInvalid struct {
+	Pin  int    `env:"PIN,secret"`
+	Keys []int  `env:"KEYS,secret"`
+	URL  Secret `env:"URL,require,expand"`
+}
+
+func TestSecretParse(t *testing.T) {
+	assert := assertWrap(t)
+	{
+		test := TestSecretParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"PASSWORD": "p@ss", "TOKEN": "t0ken"}})
+		assert("TestSecretParse", string(test.Password), "p@ss")
+		assert("TestSecretParse", test.Token, "t0ken")
+		assert("TestSecretParse", test.Password.String(), Redacted)
+		assert("TestSecretParse", fmt.Sprintf("%v %+v %#v", test.Password, test, test.Password), Redacted+" {Password:"+Redacted+" Token:t0ken} "+Redacted)
+		b, _ := json.Marshal(test)
+		assert("TestSecretParse", string(b), `{"Password":"******","Token":"t0ken"}`)
+		assert("TestSecretParse", err, nil)
+	}
+	{
+		test := TestSecretParseInvalid{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptCollect, Source: MapEnv{
+			"PIN":  "12a4",
+			"KEYS": "1,s3cr3t",
+			"URL":  "postgres://${USER}:p@ss",
+		}})
+		var errs *Errors
+		assert("TestSecretParse", errors.As(err, &errs), true)
+		assert("TestSecretParse", len(errs.Errors), 3)
+		assert("TestSecretParse", errs.Errors[0].Value, Redacted)
+		assert("TestSecretParse", errs.Errors[0].Secret, true)
+		assert("TestSecretParse", errs.Errors[0].EnvName, "PIN")
+		assert("TestSecretParse", err.Error(), "Pin invalid [******]; Keys[1] invalid [******]; URL require")
+		assert("TestSecretParse", strings.Contains(err.Error(), "p@ss"), false)
+		assert("TestSecretParse", errors.Is(err, ErrInvalidValue), true)
+	}
+}
@@ -0,0 +1,129 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+type TestSliceParseEnv struct {
+	Hosts    []string        `env:"TEST_SLICE_HOSTS"`
+	Ports    []int           `env:"TEST_SLICE_PORTS,delim=;"`
+	Weights  []float64       `env:"TEST_SLICE_WEIGHTS"`
+	Flags    []bool          `env:"TEST_SLICE_FLAGS"`
+	Timeouts []time.Duration `env:"TEST_SLICE_TIMEOUTS"`
+	Ptrs     []*uint         `env:"TEST_SLICE_PTRS"`
+	Default  []string        `default:"a|b" env:",delim=|"`
+	Empty    []string
+	NotEmpty []string
+}
+
+type TestSliceParseInvalid struct {
+	Ports []int `env:"TEST_SLICE_INVALID_PORTS"`
+}
+
+type TestSliceParseNotSupport struct {
+	Maps []map[string]string `env:"TEST_SLICE_MAPS"`
+}
+
+func TestSliceParse(t *testing.T) {
+	assert := assertWrap(t)
+	_ = os.Setenv("TEST_SLICE_HOSTS", "a, b,c")
+	_ = os.Setenv("TEST_SLICE_PORTS", "80;443")
+	_ = os.Setenv("TEST_SLICE_WEIGHTS", "0.5,1.5")
+	_ = os.Setenv("TEST_SLICE_FLAGS", "true,false,1")
+	_ = os.Setenv("TEST_SLICE_TIMEOUTS", "1s,2m")
+	_ = os.Setenv("TEST_SLICE_PTRS", "1,2")
+	_ = os.Setenv("TEST_SLICE_INVALID_PORTS", "80,xxx")
+	_ = os.Setenv("TEST_SLICE_MAPS", "a")
+	{
+		test := TestSliceParseEnv{NotEmpty: []string{"x"}}
+		err := Parse(&test)
+		assert("TestSliceParse", test.Hosts, []string{"a", "b", "c"})
+		assert("TestSliceParse", test.Ports, []int{80, 443})
+		assert("TestSliceParse", test.Weights, []float64{0.5, 1.5})
+		assert("TestSliceParse", test.Flags, []bool{true, false, true})
+		assert("TestSliceParse", test.Timeouts, []time.Duration{time.Second, 2 * time.Minute})
+		assert("TestSliceParse", len(test.Ptrs), 2)
+		assert("TestSliceParse", *test.Ptrs[1], uint(2))
+		assert("TestSliceParse", test.Default, []string{"a", "b"})
+		assert("TestSliceParse", test.Empty, []string{})
+		assert("TestSliceParse", test.NotEmpty, []string{"x"})
+		assert("TestSliceParse", err, nil)
+	}
+	{
+		test := TestSliceParseInvalid{}
+		err := Parse(&test)
+		assert("TestSliceParse", test.Ports, []int(nil))
+		assert("TestSliceParse", err.Error(), "Ports[1] invalid [xxx]")
+	}
+	{
+		test := TestSliceParseNotSupport{}
+		err := Parse(&test)
+		assert("TestSliceParse", err.Error(), "unsupport field [Maps[0]] kind [map]")
+	}
+}
+
+type TestStructSliceParseEnv struct {
+	Servers    []TestStructSliceServer  `env:"TEST_STRUCT_SLICE_SERVERS"`
+	Contiguous []TestStructSliceServer  `env:"TEST_STRUCT_SLICE_GAP"`
+	Sparse     []*TestStructSliceServer `env:"TEST_STRUCT_SLICE_GAP,sparse"`
+	Empty      []TestStructSliceServer  `env:"TEST_STRUCT_SLICE_EMPTY"`
+}
+
+type TestStructSliceServer struct {
+	Host string
+	Port int `env:",default=80"`
+}
+
+type TestStructSliceParseRequire struct {
+	Servers []TestStructSliceServer `env:"TEST_STRUCT_SLICE_REQUIRE,require"`
+}
+
+type TestStructSliceParseInvalid struct {
+	Servers []TestStructSliceServer `env:"TEST_STRUCT_SLICE_INVALID"`
+}
+
+func TestStructSliceParse(t *testing.T) {
+	assert := assertWrap(t)
+	_ = os.Setenv("TEST_STRUCT_SLICE_SERVERS_0_HOST", "a")
+	_ = os.Setenv("TEST_STRUCT_SLICE_SERVERS_1_HOST", "b")
+	_ = os.Setenv("TEST_STRUCT_SLICE_SERVERS_1_PORT", "8080")
+	_ = os.Setenv("TEST_STRUCT_SLICE_GAP_0_HOST", "a")
+	_ = os.Setenv("TEST_STRUCT_SLICE_GAP_2_HOST", "c")
+	_ = os.Setenv("TEST_STRUCT_SLICE_INVALID_0_PORT", "1")
+	_ = os.Setenv("TEST_STRUCT_SLICE_INVALID_1_PORT", "xxx")
+	{
+		test := TestStructSliceParseEnv{}
+		err := Parse(&test)
+		assert("TestStructSliceParse", test.Servers, []TestStructSliceServer{
+			{Host: "a", Port: 80},
+			{Host: "b", Port: 8080},
+		})
+		assert("TestStructSliceParse", test.Contiguous, []TestStructSliceServer{{Host: "a", Port: 80}})
+		assert("TestStructSliceParse", len(test.Sparse), 3)
+		assert("TestStructSliceParse", *test.Sparse[1], TestStructSliceServer{Port: 80})
+		assert("TestStructSliceParse", *test.Sparse[2], TestStructSliceServer{Host: "c", Port: 80})
+		assert("TestStructSliceParse", test.Empty, []TestStructSliceServer{})
+		assert("TestStructSliceParse", err, nil)
+	}
+	{
+		test := TestStructSliceParseRequire{}
+		err := Parse(&test)
+		assert("TestStructSliceParse", err.Error(), "TEST_STRUCT_SLICE_REQUIRE require")
+	}
+	{
+		test := TestStructSliceParseInvalid{}
+		err := Parse(&test)
+		assert("TestStructSliceParse", test.Servers, []TestStructSliceServer(nil))
+		assert("TestStructSliceParse", err.Error(), "Servers[1].Port invalid [xxx]")
+	}
+	{
+		test := TestStructSliceParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"TEST_STRUCT_SLICE_GAP_900000000000000000_HOST": "x"}})
+		assert("TestStructSliceParse", test.Sparse, []*TestStructSliceServer(nil))
+		assert("TestStructSliceParse", errors.Is(err, ErrInvalidValue), true)
+		assert("TestStructSliceParse", err.Error(), "Sparse invalid [900000000000000000]: index out of range, max [65535]")
+	}
+}
@@ -0,0 +1,121 @@
+package env
+
+import (
+	"testing"
+)
+
+type TestSourceParseEnv struct {
+	Addr     string
+	Port     int               `env:",require"`
+	Labels   map[string]string `env:",prefixmap"`
+	Servers  []TestSourceServer
+	Postgres TestSourceServer `env:"PG"`
+}
+
+type TestSourceServer struct {
+	Host string
+}
+
+type TestSourceLookuper struct{}
+
+func (TestSourceLookuper) Lookup(key string) (string, bool) {
+	return "lookup", key == "ADDR"
+}
+
+func TestSourceParse(t *testing.T) {
+	assert := assertWrap(t)
+	{
+		test := TestSourceParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{
+			"ADDR":           "localhost",
+			"PORT":           "80",
+			"LABELS_TEAM":    "core",
+			"SERVERS_0_HOST": "a",
+			"SERVERS_1_HOST": "b",
+			"PG_HOST":        "pg",
+		}})
+		assert("TestSourceParse", test.Addr, "localhost")
+		assert("TestSourceParse", test.Port, 80)
+		assert("TestSourceParse", test.Labels, map[string]string{"TEAM": "core"})
+		assert("TestSourceParse", test.Servers, []TestSourceServer{{Host: "a"}, {Host: "b"}})
+		assert("TestSourceParse", test.Postgres.Host, "pg")
+		assert("TestSourceParse", err, nil)
+	}
+	{
+		test := TestSourceParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: SliceEnv{
+			"ADDR=localhost",
+			"PORT=80",
+			"PORT=8080",
+			"SERVERS_0_HOST=a",
+			"LABELS_TEAM=core",
+			"LABELS_TEAM=infra",
+		}})
+		assert("TestSourceParse", test.Addr, "localhost")
+		assert("TestSourceParse", test.Port, 8080)
+		assert("TestSourceParse", test.Labels, map[string]string{"TEAM": "infra"})
+		assert("TestSourceParse", test.Servers, []TestSourceServer{{Host: "a"}})
+		assert("TestSourceParse", err, nil)
+	}
+	{
+		test := TestSourceParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{}})
+		assert("TestSourceParse", test.Addr, "")
+		assert("TestSourceParse", err.Error(), "PORT require")
+	}
+	{
+		test := TestSourceParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptSilent, Source: TestSourceLookuper{}})
+		assert("TestSourceParse", test.Addr, "lookup")
+		assert("TestSourceParse", test.Labels, map[string]string{})
+		assert("TestSourceParse", test.Servers, []TestSourceServer{})
+		assert("TestSourceParse", err, nil)
+	}
+}
+
+type TestLayersParseEnv struct {
+	Addr    string
+	Port    int
+	User    string `env:",default=postgres"`
+	Missing string
+	Servers []TestSourceServer
+}
+
+func TestLayersParse(t *testing.T) {
+	assert := assertWrap(t)
+	layers := Layers{
+		{Name: "flag", Lookuper: MapEnv{"PORT": "9090"}},
+		{Name: "env", Lookuper: SliceEnv{"PORT=8080", "ADDR=localhost", "SERVERS_0_HOST=a"}},
+		{Name: "compiled", Lookuper: MapEnv{"ADDR": "0.0.0.0", "SERVERS_0_HOST": "x", "SERVERS_1_HOST": "b"}},
+	}
+	{
+		test := TestLayersParseEnv{}
+		origins := map[string]string{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: layers, Origins: origins})
+		assert("TestLayersParse", test.Addr, "localhost")
+		assert("TestLayersParse", test.Port, 9090)
+		assert("TestLayersParse", test.User, "postgres")
+		assert("TestLayersParse", test.Servers, []TestSourceServer{{Host: "a"}, {Host: "b"}})
+		assert("TestLayersParse", origins, map[string]string{
+			"Addr":            "env",
+			"Port":            "flag",
+			"User":            OriginDefault,
+			"Servers[0].Host": "env",
+			"Servers[1].Host": "compiled",
+		})
+		assert("TestLayersParse", err, nil)
+	}
+	{
+		test := TestLayersParseEnv{}
+		origins := map[string]string{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"ADDR": "localhost"}, Origins: origins})
+		assert("TestLayersParse", origins, map[string]string{"Addr": "env", "User": OriginDefault})
+		assert("TestLayersParse", err, nil)
+	}
+}
+
+func TestSliceEnvEnviron(t *testing.T) {
+	assert := assertWrap(t)
+	environ := SliceEnv{"L_A=1", "L_B=1", "L_A=2", "L_C"}.Environ()
+	assert("TestSliceEnvEnviron", environ, []string{"L_B=1", "L_A=2", "L_C"})
+}
@@ -90,6 +90,12 @@ func assertWrap(t *testing.T) func(name string, a, b interface{}) {
 	nw := nameWrap()
 	return func(name string, a, b interface{}) {
 		name = nw(name)
+		// errors are compared by message, *FieldError carries more than the text.
+		if ea, ok := a.(error); ok {
+			if eb, ok := b.(error); ok {
+				a, b = ea.Error(), eb.Error()
+			}
+		}
 		if !reflect.DeepEqual(a, b) {
 			t.Errorf("%s failure! [%v] != [%v]", name, a, b)
 		} else {
@@ -0,0 +1,87 @@
+package env
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+type TestTimeParseEnv struct {
+	Timeout  time.Duration  `env:"TEST_TIME_TIMEOUT,shared"`
+	Bare     time.Duration  `env:"TEST_TIME_BARE,shared"`
+	Seconds  time.Duration  `env:"TEST_TIME_BARE,unit=s,shared"`
+	Default  time.Duration  `env:"TEST_TIME_DEFAULT,default=1m"`
+	NotEmpty time.Duration  `env:"TEST_TIME_TIMEOUT,shared"`
+	At       time.Time      `env:"TEST_TIME_AT"`
+	Date     time.Time      `env:"TEST_TIME_DATE,layout=2006-01-02"`
+	Named    time.Time      `env:"TEST_TIME_NAMED,layout=RFC1123"`
+	Zone     *time.Location `env:"TEST_TIME_ZONE"`
+	Empty    *time.Location `env:"TEST_TIME_EMPTY"`
+}
+
+type TestTimeParseInvalidDuration struct {
+	Timeout time.Duration `env:"TEST_TIME_INVALID_DURATION"`
+}
+
+type TestTimeParseOverflow struct {
+	Timeout time.Duration `env:"T,unit=s"`
+}
+
+type TestTimeParseInvalidTime struct {
+	At time.Time `env:"TEST_TIME_INVALID_TIME"`
+}
+
+type TestTimeParseInvalidZone struct {
+	Zone *time.Location `env:"TEST_TIME_INVALID_ZONE"`
+}
+
+func TestTimeParse(t *testing.T) {
+	assert := assertWrap(t)
+	_ = os.Setenv("TEST_TIME_TIMEOUT", "30s")
+	_ = os.Setenv("TEST_TIME_BARE", "30")
+	_ = os.Setenv("TEST_TIME_AT", "2023-02-10T08:00:00Z")
+	_ = os.Setenv("TEST_TIME_DATE", "2023-02-10")
+	_ = os.Setenv("TEST_TIME_NAMED", "Fri, 10 Feb 2023 08:00:00 UTC")
+	_ = os.Setenv("TEST_TIME_ZONE", "UTC")
+	_ = os.Setenv("TEST_TIME_INVALID_DURATION", "30x")
+	_ = os.Setenv("TEST_TIME_INVALID_TIME", "2023-02-10")
+	_ = os.Setenv("TEST_TIME_INVALID_ZONE", "Nowhere/Nothing")
+	{
+		test := TestTimeParseEnv{NotEmpty: time.Second}
+		err := Parse(&test)
+		assert("TestTimeParse", test.Timeout, 30*time.Second)
+		assert("TestTimeParse", test.Bare, 30*time.Nanosecond)
+		assert("TestTimeParse", test.Seconds, 30*time.Second)
+		assert("TestTimeParse", test.Default, time.Minute)
+		assert("TestTimeParse", test.NotEmpty, time.Second)
+		assert("TestTimeParse", test.At.Equal(time.Date(2023, 2, 10, 8, 0, 0, 0, time.UTC)), true)
+		assert("TestTimeParse", test.Date.Equal(time.Date(2023, 2, 10, 0, 0, 0, 0, time.UTC)), true)
+		assert("TestTimeParse", test.Named.Hour(), 8)
+		assert("TestTimeParse", test.Zone, time.UTC)
+		assert("TestTimeParse", test.Empty, (*time.Location)(nil))
+		assert("TestTimeParse", err, nil)
+	}
+	{
+		test := TestTimeParseInvalidDuration{}
+		err := Parse(&test)
+		assert("TestTimeParse", err.Error(), `Timeout invalid [30x]: time: unknown unit "x" in duration "30x"`)
+	}
+	{
+		for _, value := range []string{"9223372036854775807", "-9223372036854775807"} {
+			test := TestTimeParseOverflow{}
+			err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"T": value}})
+			assert("TestTimeParse", test.Timeout, time.Duration(0))
+			assert("TestTimeParse", err.Error(), "Timeout invalid ["+value+"]: duration out of range")
+		}
+	}
+	{
+		test := TestTimeParseInvalidTime{}
+		err := Parse(&test)
+		assert("TestTimeParse", err.Error(), `At invalid [2023-02-10]: parsing time "2023-02-10" as "2006-01-02T15:04:05Z07:00": cannot parse "" as "T"`)
+	}
+	{
+		test := TestTimeParseInvalidZone{}
+		err := Parse(&test)
+		assert("TestTimeParse", err.Error(), "Zone invalid [Nowhere/Nothing]: unknown time zone Nowhere/Nothing")
+	}
+}
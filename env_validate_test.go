@@ -0,0 +1,86 @@
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+type TestValidateParseEnv struct {
+	Port     int     `env:"PORT,min=1,max=65535"`
+	Pool     uint    `env:"POOL,min=1"`
+	Ratio    float64 `env:"RATIO,min=0,max=1"`
+	Level    string  `env:"LEVEL,oneof=debug|info|warn,default=info"`
+	Name     string  `env:"NAME,match=^[a-z]+$,min=2,max=8"`
+	Host     string  `env:"HOST,notempty"`
+	Optional string  `env:"OPTIONAL,match=^[a-z]+$"`
+}
+
+func TestValidateParse(t *testing.T) {
+	assert := assertWrap(t)
+	valid := MapEnv{
+		"PORT":  "8080",
+		"POOL":  "10",
+		"RATIO": "0.5",
+		"NAME":  "app",
+		"HOST":  "localhost",
+	}
+	{
+		test := TestValidateParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: valid})
+		assert("TestValidateParse", test.Port, 8080)
+		assert("TestValidateParse", test.Level, "info")
+		assert("TestValidateParse", test.Name, "app")
+		assert("TestValidateParse", err, nil)
+	}
+	{
+		test := TestValidateParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptCollect, Source: MapEnv{
+			"PORT":  "70000",
+			"POOL":  "0",
+			"RATIO": "-0.5",
+			"LEVEL": "trace",
+			"NAME":  "App",
+			"HOST":  "",
+		}})
+		var errs *Errors
+		assert("TestValidateParse", errors.As(err, &errs), true)
+		assert("TestValidateParse", errors.Is(err, ErrInvalidValue), true)
+		assert("TestValidateParse", len(errs.Errors), 6)
+		assert("TestValidateParse", errs.Errors[0].Error(), "Port invalid [70000]: greater than max [65535]")
+		assert("TestValidateParse", errs.Errors[1].Error(), "Pool invalid [0]: less than min [1]")
+		assert("TestValidateParse", errs.Errors[2].Error(), "Ratio invalid [-0.5]: less than min [0]")
+		assert("TestValidateParse", errs.Errors[3].Error(), "Level invalid [trace]: not one of [debug info warn]")
+		assert("TestValidateParse", errs.Errors[4].Error(), "Name invalid [App]: not match [^[a-z]+$]")
+		assert("TestValidateParse", errs.Errors[5].Error(), "Host invalid []: empty")
+	}
+	{
+		test := TestValidateParseEnv{}
+		valid["NAME"] = "abcdefghi"
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: valid})
+		assert("TestValidateParse", err.Error(), "Name invalid [abcdefghi]: greater than max [8]")
+	}
+}
+
+func TestValidateParseTag(t *testing.T) {
+	assert := assertWrap(t)
+	{
+		test := struct {
+			Code string `env:"CODE,match=^[a-z]{1,3}$,notempty"`
+		}{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"CODE": "ab"}})
+		assert("TestValidateParseTag", test.Code, "ab")
+		assert("TestValidateParseTag", err, nil)
+		test.Code = ""
+		err = ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"CODE": "abcd"}})
+		assert("TestValidateParseTag", err.Error(), "Code invalid [abcd]: not match [^[a-z]{1,3}$]")
+	}
+	{
+		test := struct {
+			Port int `env:"PORT,requir"`
+		}{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"PORT": "80"}})
+		assert("TestValidateParseTag", errors.Is(err, ErrInvalidTag), true)
+		assert("TestValidateParseTag", err.Error(), "Port unknown tag option [requir]")
+		assert("TestValidateParseTag", test.Port, 0)
+	}
+}
@@ -0,0 +1,71 @@
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+var errTestTLS = errors.New("tls cert and key must both be set or both empty")
+
+type TestValidatorParseEnv struct {
+	Addr string
+	TLS  TestValidatorTLS
+	Ptr  *TestValidatorTLS `env:"PTR,optional"`
+}
+
+func (c TestValidatorParseEnv) Validate() error {
+	if c.Addr == "" {
+		return errors.New("addr is empty")
+	}
+	return nil
+}
+
+type TestValidatorTLS struct {
+	Cert string
+	Key  string
+}
+
+func (t *TestValidatorTLS) Validate() error {
+	if (t.Cert == "") != (t.Key == "") {
+		return errTestTLS
+	}
+	return nil
+}
+
+func TestValidatorParse(t *testing.T) {
+	assert := assertWrap(t)
+	{
+		test := TestValidatorParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"ADDR": "localhost", "TLS_CERT": "cert", "TLS_KEY": "key"}})
+		assert("TestValidatorParse", test.TLS.Key, "key")
+		assert("TestValidatorParse", err, nil)
+	}
+	{
+		test := TestValidatorParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"ADDR": "localhost", "TLS_CERT": "cert"}})
+		var fieldErr *FieldError
+		assert("TestValidatorParse", errors.As(err, &fieldErr), true)
+		assert("TestValidatorParse", fieldErr.Path, "TLS")
+		assert("TestValidatorParse", fieldErr.EnvName, "TLS")
+		assert("TestValidatorParse", errors.Is(err, errTestTLS), true)
+		assert("TestValidatorParse", err.Error(), "TLS: tls cert and key must both be set or both empty")
+	}
+	{
+		test := TestValidatorParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{"ADDR": "localhost", "PTR_KEY": "key"}})
+		assert("TestValidatorParse", err.Error(), "Ptr: tls cert and key must both be set or both empty")
+	}
+	{
+		test := TestValidatorParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv | OptCollect, Source: MapEnv{"TLS_KEY": "key", "PTR_KEY": "key"}})
+		var errs *Errors
+		assert("TestValidatorParse", errors.As(err, &errs), true)
+		assert("TestValidatorParse", err.Error(), "TLS: tls cert and key must both be set or both empty; "+
+			"Ptr: tls cert and key must both be set or both empty")
+	}
+	{
+		test := TestValidatorParseEnv{}
+		err := ParseEntity(Entity{Value: &test, Opt: OptEnv, Source: MapEnv{}})
+		assert("TestValidatorParse", err.Error(), "addr is empty")
+	}
+}
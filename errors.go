@@ -0,0 +1,149 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrRequired        = errors.New("require")
+	ErrInvalidValue    = errors.New("invalid value")
+	ErrUnsupportedType = errors.New("unsupported type")
+	ErrCollision       = errors.New("collision")
+	ErrInvalidTag      = errors.New("invalid tag")
+)
+
+// FieldError describes the failure of a single field, Err is one of
+// ErrRequired, ErrInvalidValue, ErrUnsupportedType, ErrCollision and ErrInvalidTag, or the error returned
+// by Validator, Cause is the underlying error like *strconv.NumError, it is
+// printed after the message unless it is a *strconv.NumError, or the value is
+// a secret or read from a file.
+type FieldError struct {
+	Path    string // Go field path like "Postgres.Addr".
+	EnvName string
+	Value   string
+	Kind    reflect.Kind
+	Err     error
+	Cause   error
+	// File is the NAME_FILE the value is read from, the content is never
+	// kept in Value or printed.
+	File string
+	// Secret means Value is Redacted and Cause is not printed.
+	Secret bool
+}
+
+func (e *FieldError) Error() string {
+	var msg string
+	switch e.Err {
+	case ErrRequired:
+		msg = fmt.Sprintf("%s require", e.EnvName)
+	case ErrInvalidValue:
+		msg = fmt.Sprintf("%s invalid [%s]", e.Path, e.Value)
+		if e.File != "" {
+			msg = fmt.Sprintf("%s invalid file [%s]", e.Path, e.File)
+		}
+	case ErrUnsupportedType:
+		msg = fmt.Sprintf("unsupport field [%s] kind [%v]", e.Path, e.Kind)
+	case ErrCollision:
+		msg = fmt.Sprintf("%s collision [%s]", e.Path, e.EnvName)
+	case ErrInvalidTag:
+		msg = fmt.Sprintf("%s unknown tag option [%s]", e.Path, e.Value)
+	default:
+		if e.Path == "" {
+			return e.Err.Error()
+		}
+		msg = fmt.Sprintf("%s: %v", e.Path, e.Err)
+	}
+	if e.Cause == nil {
+		return msg
+	}
+	var numErr *strconv.NumError
+	if errors.As(e.Cause, &numErr) {
+		return msg
+	}
+	var pathErr *os.PathError
+	if (e.File != "" || e.Secret) && !errors.As(e.Cause, &pathErr) {
+		return msg
+	}
+	return fmt.Sprintf("%s: %v", msg, e.Cause)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func (e *FieldError) Is(target error) bool {
+	return e.Cause != nil && errors.Is(e.Cause, target)
+}
+
+func (e *FieldError) As(target interface{}) bool {
+	return e.Cause != nil && errors.As(e.Cause, target)
+}
+
+func fieldError(p Payload, err error, value string, cause error) *FieldError {
+	t := p.tag()
+	file := valueFile(p, t)
+	if file != "" {
+		value = ""
+	}
+	secret := isSecret(p, t)
+	if secret && value != "" {
+		value = Redacted
+	}
+	return &FieldError{
+		Path:    p.path,
+		EnvName: p.envName(t, t.Name),
+		Value:   value,
+		Kind:    p.Field.Kind(),
+		Err:     err,
+		Cause:   cause,
+		File:    file,
+		Secret:  secret,
+	}
+}
+
+// Errors collects every FieldError when OptCollect is enabled.
+type Errors struct {
+	Errors []*FieldError
+}
+
+func (e *Errors) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *Errors) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Errors) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Errors) add(p Payload, err error) {
+	switch v := err.(type) {
+	case *Errors:
+		e.Errors = append(e.Errors, v.Errors...)
+	case *FieldError:
+		e.Errors = append(e.Errors, v)
+	default:
+		e.Errors = append(e.Errors, fieldError(p, err, "", nil))
+	}
+}
@@ -0,0 +1,106 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+type unresolvedError struct {
+	Name string
+}
+
+func (e *unresolvedError) Error() string {
+	return fmt.Sprintf("unresolved reference ${%s}", e.Name)
+}
+
+type cycleError struct {
+	Names []string
+}
+
+func (e *cycleError) Error() string {
+	return fmt.Sprintf("reference cycle %s", strings.Join(e.Names, " -> "))
+}
+
+/*
+expand replaces `$VAR`, `${VAR}` and `${VAR:-fallback}` in value with the
+values of source, referenced values are expanded recursively and `$$` is a
+literal `$`. an unresolvable reference is an error when strict.
+*/
+func expand(source Lookuper, value string, strict bool, stack []string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+		var name, fallback string
+		hasFallback := false
+		switch c := value[i+1]; {
+		case c == '$':
+			sb.WriteByte('$')
+			i++
+			continue
+		case c == '{':
+			end := closingBrace(value, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated ${ in [%s]", value)
+			}
+			name = value[i+2 : end]
+			if index := strings.Index(name, ":-"); index >= 0 {
+				name, fallback, hasFallback = name[:index], name[index+2:], true
+			}
+			i = end
+		case isNameChar(c):
+			end := i + 1
+			for end < len(value) && isNameChar(value[end]) {
+				end++
+			}
+			name = value[i+1 : end]
+			i = end - 1
+		default:
+			sb.WriteByte('$')
+			continue
+		}
+		resolved, err := resolve(source, name, fallback, hasFallback, strict, stack)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(resolved)
+	}
+	return sb.String(), nil
+}
+
+func resolve(source Lookuper, name, fallback string, hasFallback, strict bool, stack []string) (string, error) {
+	for _, visited := range stack {
+		if visited == name {
+			return "", &cycleError{Names: append(append([]string{}, stack...), name)}
+		}
+	}
+	value, exist := source.Lookup(name)
+	if exist && value != "" {
+		return expand(source, value, strict, append(stack, name))
+	}
+	if hasFallback {
+		return expand(source, fallback, strict, stack)
+	}
+	if strict {
+		return "", &unresolvedError{Name: name}
+	}
+	return "", nil
+}
+
+func closingBrace(value string, start int) int {
+	depth := 1
+	for i := start; i < len(value); i++ {
+		switch value[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
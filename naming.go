@@ -0,0 +1,91 @@
+package env
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NameMapper derives the env name from a struct field name.
+type NameMapper func(name string) string
+
+// NameUpper is the default NameMapper, "DatabaseURL" becomes "DATABASEURL".
+func NameUpper(name string) string {
+	return strings.ToUpper(name)
+}
+
+// NameUpperSnake maps "DatabaseURL" to "DATABASE_URL".
+func NameUpperSnake(name string) string {
+	return strings.ToUpper(strings.Join(splitWords(name), "_"))
+}
+
+// NameLowerSnake maps "DatabaseURL" to "database_url".
+func NameLowerSnake(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "_"))
+}
+
+// NameKebab maps "DatabaseURL" to "database-url".
+func NameKebab(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "-"))
+}
+
+// NameVerbatim keeps "DatabaseURL" as it is.
+func NameVerbatim(name string) string {
+	return name
+}
+
+// nameSep is the separator the mapper puts between words, it also joins names
+// to their prefix, so NameKebab reads "http-server-read-timeout".
+func nameSep(mapper NameMapper) string {
+	name := mapper("A_B")
+	if len(name) > 2 && strings.EqualFold(name[:1], "A") && strings.EqualFold(name[len(name)-1:], "B") {
+		return name[1 : len(name)-1]
+	}
+	return "_"
+}
+
+// splitWords splits CamelCase names and keeps acronyms together,
+// "MaxIdleConns" is [Max Idle Conns], "HTTPServerID" is [HTTP Server ID],
+// mixed-case acronyms like "IPv4Addr" are [IPv4 Addr], plural acronyms like
+// "UserIDs" are [User IDs]. A new word starts at the last uppercase letter of
+// a run otherwise, so "OAuth2Token" is [O Auth2 Token] and "IDsecret" is
+// [I Dsecret], tag such fields with the name.
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '_' {
+			if start < i {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		prev := runes[i-1]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower && !acronymTail(runes[i+1:])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// acronymTail reports whether the lowercase letters after an uppercase run
+// still belong to the acronym, a single letter before a digit like "v4" of
+// "IPv4", or the plural "s" before the next word like "IDs".
+func acronymTail(runes []rune) bool {
+	if len(runes) == 0 || !unicode.IsLower(runes[0]) {
+		return false
+	}
+	if len(runes) == 1 || unicode.IsUpper(runes[1]) {
+		return runes[0] == 's'
+	}
+	return unicode.IsDigit(runes[1])
+}
@@ -0,0 +1,30 @@
+package env
+
+import (
+	"reflect"
+)
+
+// Redacted replaces secret values in errors and dumps.
+const Redacted = "******"
+
+// Secret is a string which is never printed, String, GoString and MarshalText
+// all return Redacted, convert it with string(s) to read the value.
+type Secret string
+
+func (s Secret) String() string {
+	return Redacted
+}
+
+func (s Secret) GoString() string {
+	return Redacted
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(Redacted), nil
+}
+
+var secretType = reflect.TypeOf(Secret(""))
+
+func isSecret(p Payload, t tag) bool {
+	return t.Secret || p.Field.Type() == secretType
+}
@@ -0,0 +1,142 @@
+package env
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// Lookuper is the source of env values.
+type Lookuper interface {
+	Lookup(key string) (string, bool)
+}
+
+// Environer is implemented by the Lookuper which can list all of its values
+// as "KEY=VALUE", it is required by `prefixmap` and slice of struct.
+type Environer interface {
+	Environ() []string
+}
+
+// OSEnv looks up the process environment.
+type OSEnv struct{}
+
+func (OSEnv) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (OSEnv) Environ() []string {
+	return os.Environ()
+}
+
+// MapEnv looks up a plain map.
+type MapEnv map[string]string
+
+func (m MapEnv) Lookup(key string) (string, bool) {
+	value, exist := m[key]
+	return value, exist
+}
+
+func (m MapEnv) Environ() []string {
+	environ := make([]string, 0, len(m))
+	for key, value := range m {
+		environ = append(environ, key+"="+value)
+	}
+	sort.Strings(environ)
+	return environ
+}
+
+// SliceEnv looks up "KEY=VALUE" items like exec.Cmd.Env, the last one wins
+// when a key is duplicated.
+type SliceEnv []string
+
+func (s SliceEnv) Lookup(key string) (string, bool) {
+	for i := len(s) - 1; i >= 0; i-- {
+		if strings.HasPrefix(s[i], key+"=") {
+			return s[i][len(key)+1:], true
+		}
+	}
+	return "", false
+}
+
+// Environ drops the earlier items of a duplicated key, the same one Lookup skips.
+func (s SliceEnv) Environ() []string {
+	seen := make(map[string]bool, len(s))
+	environ := make([]string, len(s))
+	n := len(s)
+	for i := len(s) - 1; i >= 0; i-- {
+		key := s[i]
+		if index := strings.Index(key, "="); index >= 0 {
+			key = key[:index]
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		n--
+		environ[n] = s[i]
+	}
+	return environ[n:]
+}
+
+func environ(source Lookuper) []string {
+	if e, ok := source.(Environer); ok {
+		return e.Environ()
+	}
+	return nil
+}
+
+// OriginDefault is recorded in Entity.Origins when a field takes its value
+// from the `default=` option or the `default` tag.
+const OriginDefault = "default"
+
+// Layer is a named Lookuper of Layers.
+type Layer struct {
+	Name string
+	Lookuper
+}
+
+// Layers looks up every Layer in order, the first one holding the key wins,
+// e.g. command-line overrides > OS env > .env file > compiled defaults.
+type Layers []Layer
+
+func (l Layers) Lookup(key string) (string, bool) {
+	value, _, exist := l.LookupLayer(key)
+	return value, exist
+}
+
+// LookupLayer also returns the name of the Layer holding the key.
+func (l Layers) LookupLayer(key string) (string, string, bool) {
+	for _, layer := range l {
+		if value, exist := layer.Lookup(key); exist {
+			return value, layer.Name, true
+		}
+	}
+	return "", "", false
+}
+
+func (l Layers) Environ() []string {
+	var merged []string
+	seen := map[string]bool{}
+	for _, layer := range l {
+		for _, kv := range environ(layer.Lookuper) {
+			key := kv
+			if index := strings.Index(kv, "="); index >= 0 {
+				key = kv[:index]
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, kv)
+		}
+	}
+	return merged
+}
+
+func origin(source Lookuper, key string) string {
+	if l, ok := source.(Layers); ok {
+		_, name, _ := l.LookupLayer(key)
+		return name
+	}
+	return "env"
+}
@@ -0,0 +1,110 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+)
+
+// Validator is implemented by structs which check themselves after their
+// fields are populated, e.g. cross-field rules.
+type Validator interface {
+	Validate() error
+}
+
+func validateStruct(value reflect.Value, path, prefix string) error {
+	if value.CanAddr() {
+		value = value.Addr()
+	}
+	if !value.CanInterface() {
+		return nil
+	}
+	v, ok := value.Interface().(Validator)
+	if !ok {
+		return nil
+	}
+	if err := v.Validate(); err != nil {
+		return &FieldError{Path: path, EnvName: prefix, Kind: reflect.Struct, Err: err}
+	}
+	return nil
+}
+
+func checkEmpty(p Payload) error {
+	if p.tag().NotEmpty {
+		return fieldError(p, ErrInvalidValue, "", fmt.Errorf("empty"))
+	}
+	return nil
+}
+
+/*
+validate checks the converted value against the rules in the tag.
+`env:"field,min=1,max=65535"` range of numbers, or length of strings.
+`env:"field,oneof=debug|info|warn"` allowed values.
+`env:"field,match=^[a-z]{1,3}$"` regular expression, "," is kept unless an option follows it.
+`env:"field,notempty"` value can not be empty.
+*/
+func validate(p Payload, value string) error {
+	t := p.tag()
+	if t.Min != "" || t.Max != "" {
+		n, err := number(p.Field)
+		if err != nil {
+			return fieldError(p, ErrInvalidValue, value, err)
+		}
+		if t.Min != "" {
+			min, err := strconv.ParseFloat(t.Min, 64)
+			if err != nil {
+				return fieldError(p, ErrInvalidValue, value, fmt.Errorf("invalid rule min=%s", t.Min))
+			}
+			if n < min {
+				return fieldError(p, ErrInvalidValue, value, fmt.Errorf("less than min [%s]", t.Min))
+			}
+		}
+		if t.Max != "" {
+			max, err := strconv.ParseFloat(t.Max, 64)
+			if err != nil {
+				return fieldError(p, ErrInvalidValue, value, fmt.Errorf("invalid rule max=%s", t.Max))
+			}
+			if n > max {
+				return fieldError(p, ErrInvalidValue, value, fmt.Errorf("greater than max [%s]", t.Max))
+			}
+		}
+	}
+	if len(t.OneOf) != 0 {
+		found := false
+		for _, one := range t.OneOf {
+			if one == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fieldError(p, ErrInvalidValue, value, fmt.Errorf("not one of %v", t.OneOf))
+		}
+	}
+	if t.Match != "" {
+		re, err := regexp.Compile(t.Match)
+		if err != nil {
+			return fieldError(p, ErrInvalidValue, value, fmt.Errorf("invalid rule match=%s", t.Match))
+		}
+		if !re.MatchString(value) {
+			return fieldError(p, ErrInvalidValue, value, fmt.Errorf("not match [%s]", t.Match))
+		}
+	}
+	return nil
+}
+
+// number returns the number to compare with min and max, the length for strings.
+func number(field reflect.Value) (float64, error) {
+	switch field.Kind() {
+	case reflect.String:
+		return float64(len(field.String())), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), nil
+	}
+	return 0, fmt.Errorf("min and max are unsupported for kind [%v]", field.Kind())
+}